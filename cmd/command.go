@@ -7,19 +7,26 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 
 	"github.com/joshdk/buildversion"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/joshdk/krf/cmd/mflag"
 	"github.com/joshdk/krf/config"
+	"github.com/joshdk/krf/diff"
+	"github.com/joshdk/krf/edit"
 	"github.com/joshdk/krf/matcher"
+	"github.com/joshdk/krf/parallel"
 	"github.com/joshdk/krf/printer"
 	"github.com/joshdk/krf/resolver"
 	"github.com/joshdk/krf/resources"
@@ -28,14 +35,14 @@ import (
 // Command returns a complete command line handler for krf.
 func Command() *cobra.Command { //nolint:funlen,maintidx
 	cmd := &cobra.Command{
-		Use:     "krf [directory|file|-]",
+		Use:     "krf [[name=]directory|chart|kustomization|file|glob|revision:path|-]...",
 		Long:    "krf - kubernetes resource filter",
 		Version: "-",
 
 		SilenceUsage:  true,
 		SilenceErrors: true,
 
-		Args: cobra.MaximumNArgs(1),
+		Args: cobra.ArbitraryArgs,
 	}
 
 	// Set a custom list of examples.
@@ -49,6 +56,53 @@ func Command() *cobra.Command { //nolint:funlen,maintidx
 
 	mf := mflag.NewMatcherFlags(cmd.Flags())
 
+	// diffOptions configures how --diff resources are paired and compared,
+	// decodeOptions configures how all resources are decoded, and sources
+	// are the (optionally labelled) sources of resources. All are populated
+	// once flags and configuration are loaded.
+	var (
+		diffOptions   diff.Options
+		decodeOptions []resources.Option
+		sources       []source
+	)
+
+	// baselines holds the resources decoded from each --diff baseline, so
+	// that each is only decoded (and its problems reported) once.
+	baselines := make(map[string][]resources.Resource)
+
+	decodeBaseline := func(filename string) ([]resources.Resource, error) {
+		filename = diffBaseline(filename, primarySource(sources))
+
+		if items, found := baselines[filename]; found {
+			return items, nil
+		}
+
+		var items []resources.Resource
+
+		if err := resources.Decode(filename, func(item resources.Resource) {
+			items = append(items, item)
+		}, decodeOptions...); err != nil {
+			return nil, err
+		}
+
+		baselines[filename] = items
+
+		return items, nil
+	}
+
+	// newDiffMatcher creates a diff matcher which is restricted to the
+	// statuses given by the --diff-status flag.
+	newDiffMatcher := func(filename string) (matcher.Matcher, error) {
+		statuses, _ := cmd.Flags().GetStringSlice("diff-status")
+
+		baseline, err := decodeBaseline(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		return matcher.NewDiffBaselineMatcher(baseline, statuses, diffOptions)
+	}
+
 	// Define --annotation flag.
 	mf.StringSliceMatcher(matcher.NewAnnotationMatcher,
 		"annotation",
@@ -94,15 +148,25 @@ func Command() *cobra.Command { //nolint:funlen,maintidx
 		"not-contains",
 		"exclude resources by substring contents")
 
+	// Define --dangling-references flag.
+	mf.BoolMatcher(matcher.NewDanglingReferenceMatcher,
+		"dangling-references",
+		"include resources that reference missing resources")
+
 	// Define --diff flag.
-	mf.StringMatcher(matcher.NewDiffMatcher,
+	mf.StringMatcher(newDiffMatcher,
 		"diff",
-		"include resources which differ from those in a file")
+		"include resources which differ from those in a file or git revision")
 
 	// Define --not-diff flag.
-	mf.StringMatcher(matcher.NewDiffMatcher,
+	mf.StringMatcher(newDiffMatcher,
 		"not-diff",
-		"exclude resources which differ from those in a file")
+		"exclude resources which differ from those in a file or git revision")
+
+	// Define --duplicates flag.
+	mf.BoolMatcher(matcher.NewDuplicateMatcher,
+		"duplicates",
+		"include resources that share an API group, kind, namespace, and name with another resource")
 
 	// Define --exec flag.
 	mf.StringMatcher(matcher.NewExecMatcher,
@@ -114,6 +178,12 @@ func Command() *cobra.Command { //nolint:funlen,maintidx
 		"not-exec",
 		"exclude resources by executing a script")
 
+	// Define --filter flag.
+	mf.ExpressionMatcher(
+		"filter",
+		"f",
+		"include resources by boolean filter expression")
+
 	// Define --fieldpath flag.
 	mf.StringSliceMatcher(matcher.NewFieldPathMatcher,
 		"fieldpath",
@@ -134,6 +204,26 @@ func Command() *cobra.Command { //nolint:funlen,maintidx
 		"not-git",
 		"exclude resources by git status")
 
+	// Define --git-author flag.
+	mf.StringSliceMatcher(matcher.NewGitAuthorMatcher,
+		"git-author",
+		"include resources last modified by a git author")
+
+	// Define --not-git-author flag.
+	mf.StringSliceMatcher(matcher.NewGitAuthorMatcher,
+		"not-git-author",
+		"exclude resources last modified by a git author")
+
+	// Define --git-since flag.
+	mf.StringMatcher(matcher.NewGitSinceMatcher,
+		"git-since",
+		"include resources modified since a date or duration")
+
+	// Define --not-git-since flag.
+	mf.StringMatcher(matcher.NewGitSinceMatcher,
+		"not-git-since",
+		"exclude resources modified since a date or duration")
+
 	// Define --jsonpath flag.
 	mf.StringSliceMatcher(matcher.NewJsonpathMatcher,
 		"jsonpath",
@@ -189,6 +279,16 @@ func Command() *cobra.Command { //nolint:funlen,maintidx
 		"namespace-scoped",
 		"include resources that are namespace-scoped")
 
+	// Define --no-service flag.
+	mf.BoolMatcher(matcher.NewNoServiceMatcher,
+		"no-service",
+		"include workloads that are not selected by any service")
+
+	// Define --orphaned flag.
+	mf.BoolMatcher(matcher.NewOrphanedMatcher,
+		"orphaned",
+		"include resources that are not referenced by any resource")
+
 	// Define --patch flag.
 	mf.BoolMatcher(matcher.NewPatchMatcher,
 		"patch",
@@ -199,6 +299,16 @@ func Command() *cobra.Command { //nolint:funlen,maintidx
 		"not-patch",
 		"exclude resources from patch files")
 
+	// Define --patched-by flag.
+	mf.StringSliceMatcher(matcher.NewPatchedByMatcher,
+		"patched-by",
+		"include resources patched by a kustomize patch file path")
+
+	// Define --not-patched-by flag.
+	mf.StringSliceMatcher(matcher.NewPatchedByMatcher,
+		"not-patched-by",
+		"exclude resources patched by a kustomize patch file path")
+
 	// Define --path flag.
 	mf.StringSliceMatcher(matcher.NewPathMatcher,
 		"path",
@@ -219,6 +329,16 @@ func Command() *cobra.Command { //nolint:funlen,maintidx
 		"not-references",
 		"exclude resources that reference resource")
 
+	// Define --referenced-by flag.
+	mf.StringSliceMatcher(matcher.NewReferencedByMatcher,
+		"referenced-by",
+		"include resources that are referenced by resource")
+
+	// Define --not-referenced-by flag.
+	mf.StringSliceMatcher(matcher.NewReferencedByMatcher,
+		"not-referenced-by",
+		"exclude resources that are referenced by resource")
+
 	// Define --rego flag.
 	mf.StringSliceMatcher(matcher.NewRegoMatcher,
 		"rego",
@@ -229,6 +349,16 @@ func Command() *cobra.Command { //nolint:funlen,maintidx
 		"not-rego",
 		"exclude resources that match a rego policy")
 
+	// Define --selected-by flag.
+	mf.StringSliceMatcher(matcher.NewSelectedByMatcher,
+		"selected-by",
+		"include workloads that are selected by resource")
+
+	// Define --not-selected-by flag.
+	mf.StringSliceMatcher(matcher.NewSelectedByMatcher,
+		"not-selected-by",
+		"exclude workloads that are selected by resource")
+
 	// Define --selector flag.
 	mf.StringMatcher(matcher.NewSelectorMatcher,
 		"selector",
@@ -239,12 +369,121 @@ func Command() *cobra.Command { //nolint:funlen,maintidx
 		"not-selector",
 		"exclude resources by label selector")
 
+	// Define --selects flag.
+	mf.StringSliceMatcher(matcher.NewSelectsMatcher,
+		"selects",
+		"include resources that select workload")
+
+	// Define --not-selects flag.
+	mf.StringSliceMatcher(matcher.NewSelectsMatcher,
+		"not-selects",
+		"exclude resources that select workload")
+
+	// Define --selects-nothing flag.
+	mf.BoolMatcher(matcher.NewSelectsNothingMatcher,
+		"selects-nothing",
+		"include resources with a selector that selects no workloads")
+
+	// Define --source flag.
+	mf.StringSliceMatcher(matcher.NewSourceMatcher,
+		"source",
+		"include resources by source label")
+
+	// Define --not-source flag.
+	mf.StringSliceMatcher(matcher.NewSourceMatcher,
+		"not-source",
+		"exclude resources by source label")
+
 	// Define --config flag.
 	cfgfile := cmd.Flags().String(
 		"config",
 		"~/.config/krf/configuration.yaml",
 		"path to config file")
 
+	// Define --diff-identity flag.
+	cmd.Flags().StringSlice(
+		"diff-identity",
+		nil,
+		"parts of resource identity ignored when pairing --diff resources (api-version,hash-suffix,namespace,name-prefix=...,name-suffix=...)")
+
+	// Define --diff-ignore flag.
+	cmd.Flags().StringArray(
+		"diff-ignore",
+		nil,
+		"json path of fields ignored when comparing --diff resources")
+
+	// Define --diff-ignore-cel flag.
+	cmd.Flags().StringArray(
+		"diff-ignore-cel",
+		nil,
+		"cel rule (using path, before, and after) for changes ignored when comparing --diff resources")
+
+	// Define --diff-rename flag.
+	cmd.Flags().StringToString(
+		"diff-rename",
+		nil,
+		"pair --diff resources that were renamed (original=modified)")
+
+	// Define --diff-status flag.
+	diffStatuses := cmd.Flags().StringSlice(
+		"diff-status",
+		nil,
+		"classify resources in both --diff inputs by status (added,changed,removed,unchanged)")
+
+	// Define --exclude flag.
+	cmd.Flags().StringSlice(
+		"exclude",
+		nil,
+		"globs for files and directories skipped when walking directories")
+
+	// Define --extract flag.
+	extract := cmd.Flags().Bool(
+		"extract",
+		false,
+		"remove matching resources from their files")
+
+	// Define --extract-to flag.
+	extractTo := cmd.Flags().String(
+		"extract-to",
+		"",
+		"move matching resources from their files into a directory laid out by kind and name")
+
+	// Define --follow-symlinks flag.
+	cmd.Flags().Bool(
+		"follow-symlinks",
+		false,
+		"walk symlinked directories")
+
+	// Define --helm-values flag.
+	helmValues := cmd.Flags().StringSlice(
+		"helm-values",
+		nil,
+		"values files used when rendering helm charts")
+
+	// Define --include flag.
+	cmd.Flags().StringSlice(
+		"include",
+		nil,
+		"globs for files decoded when walking directories (default *.yaml)")
+
+	// Define --kustomize flag.
+	kustomize := cmd.Flags().Bool(
+		"kustomize",
+		false,
+		"build directories as kustomizations")
+
+	// Define --max-depth flag.
+	cmd.Flags().Int(
+		"max-depth",
+		0,
+		"maximum number of directories deep to walk (0 is unlimited)")
+
+	// Define --no-ignore-files flag.
+	cmd.Flags().Bool(
+		"no-ignore-files",
+		false,
+		"disregard .gitignore and .krfignore files when walking directories")
+
 	// Define --no-simplify flag.
 	noSimplify := cmd.Flags().Bool(
 		"no-simplify",
@@ -257,15 +496,66 @@ func Command() *cobra.Command { //nolint:funlen,maintidx
 		"output",
 		"o",
 		"",
-		"output format (json,name,path,references,selector,table,yaml)")
+		"output format (blame,custom-columns=...,dangling,diff,go-template=...,graph,json,jsonpath=...,location,mermaid,name,path,references,selections,selector,table,yaml)")
+
+	// Define --output-dir flag.
+	outputDir := cmd.Flags().String(
+		"output-dir",
+		"",
+		"write each resource to its own file within a directory")
+
+	// Define --output-dir-kustomization flag.
+	outputDirKustomization := cmd.Flags().Bool(
+		"output-dir-kustomization",
+		false,
+		"also write a kustomization.yaml listing each --output-dir file")
+
+	// Define --output-dir-template flag.
+	outputDirTemplate := cmd.Flags().String(
+		"output-dir-template",
+		printer.DefaultDirectoryTemplate,
+		"template for the name of each --output-dir file (name,namespace,kind,apiVersion,group,version,source)")
+
+	// Define --patch-file flag.
+	patchFiles := cmd.Flags().StringArray(
+		"patch-file",
+		nil,
+		"strategic merge patch file applied in place to matching resources")
+
+	// Define --set flag.
+	setExpressions := cmd.Flags().StringArray(
+		"set",
+		nil,
+		"fieldpath and yaml value set in place on matching resources (.spec.replicas=3)")
+
+	// Define --sort flag.
+	sortOrder := cmd.Flags().String(
+		"sort",
+		"path",
+		"order of printed resources (path,none), where none prints yaml, json, and name output as resources are matched")
+
+	// Define --strict flag.
+	strict := cmd.Flags().Bool(
+		"strict",
+		false,
+		"warn about and fail on any skipped or broken documents")
+
+	// Define --warnings flag.
+	warnings := cmd.Flags().Bool(
+		"warnings",
+		false,
+		"warn about any skipped or broken documents")
 
 	var state struct {
 		allMatchers matcher.Matcher
 		printerFn   func(io.Writer, []resources.Resource) error
-		source      string
+		corpus      []resources.Resource
+		baseline    []resources.Resource
+		editFn      resources.EditFunc
+		problems    int
 	}
 
-	cmd.PreRunE = func(_ *cobra.Command, args []string) error {
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		configFilename := *cfgfile
 		if strings.HasPrefix(configFilename, "~/") {
 			configFilename = filepath.Join(os.Getenv("HOME"), configFilename[2:])
@@ -278,47 +568,512 @@ func Command() *cobra.Command { //nolint:funlen,maintidx
 
 		resolver.Init(cfg.Resources)
 
-		state.printerFn, err = printer.ByName(*output)
+		diffOptions, err = newDiffOptions(cmd.Flags(), cfg.Diff)
+		if err != nil {
+			return err
+		}
+
+		state.printerFn, err = printer.ByName(*output, printer.Options{
+			Corpus: func() []resources.Resource {
+				return state.corpus
+			},
+			Baseline: func() []resources.Resource {
+				return state.baseline
+			},
+			Diff: diffOptions,
+			Locate: func(item resources.Resource) []int {
+				return matcher.Locate(state.allMatchers, item)
+			},
+		})
 		if err != nil {
 			return err
 		}
 
+		// Resources are written to files instead, when an output directory is
+		// given.
+		switch {
+		case *outputDir != "" && *output != "":
+			return errors.New("the --output and --output-dir flags cannot be combined")
+		case *outputDir != "":
+			state.printerFn, err = printer.Directory(printer.DirectoryOptions{
+				Directory:     *outputDir,
+				Template:      *outputDirTemplate,
+				Kustomization: *outputDirKustomization,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if *sortOrder != "path" && *sortOrder != "none" {
+			return fmt.Errorf("unknown sort order: %s", *sortOrder)
+		}
+
+		walkOptions, err := newWalkOptions(cmd.Flags(), cfg.Walk)
+		if err != nil {
+			return err
+		}
+
+		decodeOptions = append(decodeOptions, resources.WithHelmValues(*helmValues...))
+		decodeOptions = append(decodeOptions, walkOptions...)
+
+		if *kustomize {
+			// Kustomizations list their own resources, so directories are
+			// never walked.
+			for _, name := range []string{"include", "exclude", "no-ignore-files", "follow-symlinks", "max-depth"} {
+				if cmd.Flags().Changed(name) {
+					return fmt.Errorf("the --%s flag cannot be combined with --kustomize", name)
+				}
+			}
+
+			decodeOptions = append(decodeOptions, resources.WithKustomize())
+		}
+
+		// Every document that is skipped while decoding is reported as a
+		// warning, instead of being silently ignored.
+		if *strict || *warnings {
+			decodeOptions = append(decodeOptions, resources.WithDiagnostics(func(diagnostic resources.Diagnostic) {
+				state.problems++
+
+				fmt.Fprintln(cmd.ErrOrStderr(), "krf: warning:", diagnostic)
+			}))
+		}
+
+		if sources, err = parseSources(args); err != nil {
+			return err
+		}
+
 		state.allMatchers, err = mf.Matcher()
 		if err != nil {
 			return err
 		}
 
-		if len(args) > 0 {
-			state.source = args[0]
+		// Matching resources are edited in place by applying every patch file,
+		// followed by every set expression.
+		var edits []resources.EditFunc
+
+		for _, filename := range *patchFiles {
+			editFn, err := edit.PatchFile(filename)
+			if err != nil {
+				return err
+			}
+
+			edits = append(edits, editFn)
+		}
+
+		for _, expression := range *setExpressions {
+			editFn, err := edit.Set(expression)
+			if err != nil {
+				return err
+			}
+
+			edits = append(edits, editFn)
+		}
+
+		if len(edits) > 0 {
+			state.editFn = edit.Chain(edits...)
+		}
+
+		if state.editFn != nil && (*extract || *extractTo != "") {
+			return errors.New("the --extract and --extract-to flags cannot be combined with --set or --patch-file")
+		}
+
+		// Resources are classified against the --diff baseline, which means
+		// also emitting resources that only exist in the baseline, when either
+		// explicitly requested or when using the diff printer.
+		diffFilename, _ := cmd.Flags().GetString("diff")
+
+		switch {
+		case isBareRevision(diffFilename) && len(sources) > 1:
+			return errors.New("a --diff git revision requires a single source")
+		case diffFilename == "" && *output == "diff":
+			return errors.New("the diff output format requires --diff")
+		case diffFilename == "" && len(*diffStatuses) > 0:
+			return errors.New("the --diff-status flag requires --diff")
+		case diffFilename != "" && (*output == "diff" || len(*diffStatuses) > 0):
+			baseline, err := decodeBaseline(diffFilename)
+			if err != nil {
+				return err
+			}
+
+			// The baseline is copied, as the diff matcher compares against
+			// the original (unsimplified) resources.
+			for _, item := range baseline {
+				item.Unstructured = *item.DeepCopy()
+				state.baseline = append(state.baseline, item)
+			}
+
+			if !*noSimplify {
+				simplifyResources(state.baseline)
+			}
 		}
 
 		return nil
 	}
 
-	cmd.RunE = func(*cobra.Command, []string) error {
-		var results []resources.Resource
+	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		// Resources are printed as soon as each is matched (without holding
+		// every resource in memory) when they are not sorted, and when nothing
+		// needs to examine every resource first.
+		stream := printer.Streaming(*output)
+
+		switch {
+		case *sortOrder != "none", *outputDir != "", state.editFn != nil, *extract, *extractTo != "":
+			stream = nil
+		case state.baseline != nil, matcher.NeedsCorpus(state.allMatchers):
+			stream = nil
+		}
+
+		if stream != nil {
+			if err := streamResources(os.Stdout, sources, decodeOptions, state.allMatchers, stream, !*noSimplify); err != nil {
+				return err
+			}
+
+			if *strict && state.problems > 0 {
+				return fmt.Errorf("found %d skipped or broken documents", state.problems)
+			}
+
+			return nil
+		}
+
+		// Decode every resource up front, as some matchers need to examine
+		// the entire corpus before matching individual resources.
+		for _, source := range sources {
+			err := resources.Decode(source.path, func(item resources.Resource) {
+				state.corpus = append(state.corpus, item)
+			}, append(slices.Clip(decodeOptions), resources.WithSource(source.name))...)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Fail before matching (or editing) any resources when any documents
+		// were skipped, so that the check can be used to gate a CI pipeline.
+		if *strict && state.problems > 0 {
+			return fmt.Errorf("found %d skipped or broken documents", state.problems)
+		}
+
+		// Emit each resource that only exists in the diff baseline, so that
+		// its removal can be matched and printed. Removed resources are not
+		// part of the corpus, so that matchers (and printers) examining the
+		// entire corpus do not consider them to still be present.
+		var removed []resources.Resource
+
+		present := make(map[string]bool, len(state.corpus))
+		for _, item := range state.corpus {
+			present[diffOptions.Identity.Key(item.Unstructured)] = true
+		}
+
+		for _, original := range state.baseline {
+			if !present[diffOptions.Identity.OriginalKey(original.Unstructured)] {
+				removed = append(removed, original.AsRemoved())
+			}
+		}
+
+		var (
+			results  []resources.Resource
+			dangling int
+			err      error
+		)
+
+		// Matching resources with dangling references are counted, whether
+		// matched by --dangling-references or within a --filter expression.
+		danglingMatcher := matcher.DanglingReferences(state.allMatchers)
+
+		// Resources from each labelled source are matched separately, so that
+		// matchers examining the entire corpus (like --duplicates) only
+		// consider the resources from the same source. Resources from every
+		// unlabelled source are matched together.
+		for _, group := range groupBySource(append(slices.Clip(state.corpus), removed...)) {
+			matcher.Prepare(state.allMatchers, slices.DeleteFunc(slices.Clone(group), resources.Resource.IsRemoved))
+
+			matches := parallel.Map(0, group, state.allMatchers.Matches)
+
+			for index, item := range group {
+				if !matches[index] {
+					continue
+				}
 
-		err := resources.Decode(state.source, func(item resources.Resource) {
-			if state.allMatchers.Matches(item) {
 				results = append(results, item)
+
+				if danglingMatcher != nil && danglingMatcher.Matches(item) {
+					dangling++
+				}
+			}
+		}
+
+		// Rewrite (or remove) the matching resources in their original files,
+		// before they are simplified for printing.
+		if state.editFn != nil {
+			if results, err = resources.Edit(results, state.editFn); err != nil {
+				return err
+			}
+		}
+
+		if *extract || *extractTo != "" {
+			if err := extractResources(results, *extractTo); err != nil {
+				return err
 			}
-		})
-		if err != nil {
-			return err
 		}
 
 		if !*noSimplify {
 			simplifyResources(results)
 		}
 
-		sortResources(results)
+		if *sortOrder != "none" {
+			sortResources(results)
+		}
+
+		if err := state.printerFn(os.Stdout, results); err != nil {
+			return err
+		}
+
+		// Fail when any resources with dangling references were found, so
+		// that the check can be used to gate a CI pipeline.
+		if dangling > 0 {
+			return fmt.Errorf("found %d resources with dangling references", dangling)
+		}
 
-		return state.printerFn(os.Stdout, results)
+		return nil
 	}
 
 	return cmd
 }
 
+// newDiffOptions returns the diff.Options configured by the --diff-identity,
+// --diff-rename, --diff-ignore, and --diff-ignore-cel flags, along with any
+// ignore rules from the given configuration.
+func newDiffOptions(flags *pflag.FlagSet, cfg config.Diff) (diff.Options, error) {
+	strategies, err := flags.GetStringSlice("diff-identity")
+	if err != nil {
+		return diff.Options{}, err
+	}
+
+	renames, err := flags.GetStringToString("diff-rename")
+	if err != nil {
+		return diff.Options{}, err
+	}
+
+	identity, err := diff.ParseIdentity(strategies, renames)
+	if err != nil {
+		return diff.Options{}, err
+	}
+
+	paths, err := flags.GetStringArray("diff-ignore")
+	if err != nil {
+		return diff.Options{}, err
+	}
+
+	rules, err := flags.GetStringArray("diff-ignore-cel")
+	if err != nil {
+		return diff.Options{}, err
+	}
+
+	ignore, err := diff.ParseIgnore(append(cfg.Ignore, paths...), append(cfg.IgnoreCEL, rules...))
+	if err != nil {
+		return diff.Options{}, err
+	}
+
+	return diff.Options{Identity: identity, Ignore: ignore}, nil
+}
+
+// newWalkOptions returns the resources.Option list configured by the
+// --include, --exclude, --no-ignore-files, --follow-symlinks, and --max-depth
+// flags, along with any walk settings from the given configuration. Flags
+// take precedence over the configuration.
+func newWalkOptions(flags *pflag.FlagSet, cfg config.Walk) ([]resources.Option, error) {
+	include, err := flags.GetStringSlice("include")
+	if err != nil {
+		return nil, err
+	}
+
+	exclude, err := flags.GetStringSlice("exclude")
+	if err != nil {
+		return nil, err
+	}
+
+	noIgnoreFiles, err := flags.GetBool("no-ignore-files")
+	if err != nil {
+		return nil, err
+	}
+
+	followSymlinks, err := flags.GetBool("follow-symlinks")
+	if err != nil {
+		return nil, err
+	}
+
+	maxDepth := cfg.MaxDepth
+	if flags.Changed("max-depth") {
+		if maxDepth, err = flags.GetInt("max-depth"); err != nil {
+			return nil, err
+		}
+	}
+
+	// Included files given as flags replace those from the configuration,
+	// while excluded files are combined.
+	if len(include) == 0 {
+		include = cfg.Include
+	}
+
+	opts := []resources.Option{
+		resources.WithInclude(include...),
+		resources.WithExclude(append(cfg.Exclude, exclude...)...),
+		resources.WithMaxDepth(maxDepth),
+	}
+
+	if noIgnoreFiles || cfg.NoIgnoreFiles {
+		opts = append(opts, resources.WithoutIgnoreFiles())
+	}
+
+	if followSymlinks || cfg.FollowSymlinks {
+		opts = append(opts, resources.WithFollowSymlinks())
+	}
+
+	return opts, nil
+}
+
+// isBareRevision reports if the given --diff baseline is a bare git revision
+// (like "HEAD~1"), opposed to a file or a git revision and path.
+func isBareRevision(baseline string) bool {
+	if _, err := os.Stat(baseline); err == nil || strings.Contains(baseline, ":") {
+		return false
+	}
+
+	return resources.IsRevision(baseline)
+}
+
+// diffBaseline returns the source of the --diff baseline resources. A bare git
+// revision (like "HEAD~1") refers to the given source as it existed at that
+// revision.
+func diffBaseline(baseline, source string) string {
+	if !isBareRevision(baseline) {
+		return baseline
+	}
+
+	// Sources that are themselves a git revision refer to the same path.
+	if _, err := os.Stat(source); err != nil {
+		_, source = resources.SplitRevision(source)
+	}
+
+	switch {
+	case source == "" || source == "-":
+		return baseline + ":."
+	case filepath.IsAbs(source), source == ".", strings.HasPrefix(source, "./"), strings.HasPrefix(source, "../"):
+		return baseline + ":" + source
+	default:
+		return baseline + ":./" + source
+	}
+}
+
+// extractResources removes the given resources from their original files. If
+// a directory is given, each resource is moved into a file at kind/name.yaml
+// within that directory, where resources that share a kind and name (like
+// those in different namespaces) share a file.
+func extractResources(items []resources.Resource, directory string) error {
+	extractPath := func(item resources.Resource) string {
+		return filepath.Join(directory, strings.ToLower(item.GetKind()), item.GetName()+".yaml")
+	}
+
+	// Refuse to overwrite any existing files, before any resources are
+	// removed.
+	if directory != "" {
+		for _, item := range items {
+			if _, err := os.Stat(extractPath(item)); err == nil {
+				return fmt.Errorf("extracting %s/%s: file %s already exists", item.GetKind(), item.GetName(), extractPath(item))
+			}
+		}
+	}
+
+	return resources.Extract(items, func(item resources.Resource, text string) error {
+		if directory == "" {
+			return nil
+		}
+
+		filename := extractPath(item)
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil { //nolint:mnd
+			return err
+		}
+
+		file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644) //nolint:mnd
+		if err != nil {
+			return err
+		}
+		defer file.Close() //nolint:errcheck
+
+		// Separate the documents of resources that share a file.
+		if info, err := file.Stat(); err == nil && info.Size() > 0 {
+			text = "---\n" + text
+		}
+
+		if _, err := file.WriteString(text); err != nil {
+			return err
+		}
+
+		return file.Close()
+	})
+}
+
+// streamResources decodes resources from each of the given sources, then
+// concurrently matches and renders them, and prints each matching resource in
+// its original order as soon as it is rendered.
+func streamResources(w io.Writer, sources []source, opts []resources.Option, allMatchers matcher.Matcher, stream *printer.Stream, simplify bool) error {
+	type result struct {
+		output  []byte
+		matches bool
+		err     error
+	}
+
+	var (
+		printed  bool
+		printErr error
+	)
+
+	pool := parallel.New(0, func(item resources.Resource) result {
+		if !allMatchers.Matches(item) {
+			return result{}
+		}
+
+		if simplify {
+			simplifyResources([]resources.Resource{item})
+		}
+
+		output, err := stream.Render(item)
+
+		return result{output: output, matches: true, err: err}
+	}, func(result result) {
+		if printErr != nil || !result.matches {
+			return
+		}
+
+		if printErr = result.err; printErr != nil {
+			return
+		}
+
+		if printed {
+			if _, printErr = io.WriteString(w, stream.Separator); printErr != nil {
+				return
+			}
+		}
+
+		printed = true
+		_, printErr = w.Write(result.output)
+	})
+
+	for _, source := range sources {
+		err := resources.Decode(source.path, pool.Submit, append(slices.Clip(opts), resources.WithSource(source.name))...)
+		if err != nil {
+			pool.Wait()
+
+			return err
+		}
+	}
+
+	pool.Wait()
+
+	return printErr
+}
+
 // simplifyResources removes a number of properties (specifically properties
 // that are automatically sey by Kubernetes after a resource is admitted) from
 // each item in the given resources.Resource list.
@@ -336,10 +1091,14 @@ func simplifyResources(items []resources.Resource) {
 	}
 }
 
-// sortResources sorts the given resources.Resource list by filename, then
-// namespace, name, and finally kind.
+// sortResources sorts the given resources.Resource list by source, filename,
+// then namespace, name, and finally kind.
 func sortResources(items []resources.Resource) {
 	slices.SortFunc(items, func(a, b resources.Resource) int {
+		if cmp := strings.Compare(a.GetSource(), b.GetSource()); cmp != 0 {
+			return cmp
+		}
+
 		if cmp := strings.Compare(a.GetFilename(), b.GetFilename()); cmp != 0 {
 			return cmp
 		}
@@ -359,3 +1118,100 @@ func sortResources(items []resources.Resource) {
 		return 0
 	})
 }
+
+// source is a single source of resources, along with an optional label.
+type source struct {
+	name string
+	path string
+}
+
+// sourceNamePattern matches a valid source label, like "prod".
+var sourceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9_.]*[a-zA-Z0-9])?$`)
+
+// parseSources parses the given command line arguments into a list of
+// sources. Each argument is optionally labelled (like "prod=./dump.yaml"),
+// and globs are expanded into a source for each matching file.
+func parseSources(args []string) ([]source, error) {
+	if len(args) == 0 {
+		return []source{{}}, nil
+	}
+
+	var (
+		sources []source
+		stdin   bool
+	)
+
+	for _, arg := range args {
+		var name, path string
+
+		// Split the label from the path, unless the argument is itself an
+		// existing file.
+		if _, err := os.Stat(arg); err == nil {
+			path = arg
+		} else if before, after, found := strings.Cut(arg, "="); found && sourceNamePattern.MatchString(before) && after != "" {
+			name, path = before, after
+		} else {
+			path = arg
+		}
+
+		if path == "-" {
+			if stdin {
+				return nil, errors.New("stdin can only be given as a source once")
+			}
+
+			stdin = true
+		}
+
+		// Expand globs that do not refer to an existing file.
+		paths := []string{path}
+
+		if _, err := os.Stat(path); err != nil && strings.ContainsAny(path, "*?[") {
+			matches, err := filepath.Glob(path)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match source %q", path)
+			}
+
+			paths = matches
+		}
+
+		for _, path := range paths {
+			sources = append(sources, source{name: name, path: path})
+		}
+	}
+
+	return sources, nil
+}
+
+// primarySource returns the path of the given sources, if there is only one.
+func primarySource(sources []source) string {
+	if len(sources) != 1 {
+		return ""
+	}
+
+	return sources[0].path
+}
+
+// groupBySource groups the given resources.Resource list by source label,
+// preserving the original order.
+func groupBySource(items []resources.Resource) [][]resources.Resource {
+	var groups [][]resources.Resource
+
+	indexes := make(map[string]int)
+
+	for _, item := range items {
+		index, found := indexes[item.GetSource()]
+		if !found {
+			index = len(groups)
+			indexes[item.GetSource()] = index
+			groups = append(groups, nil)
+		}
+
+		groups[index] = append(groups[index], item)
+	}
+
+	return groups
+}
@@ -10,6 +10,7 @@
 package mflag
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/spf13/pflag"
@@ -25,12 +26,14 @@ type FlagSet struct {
 	flags         *pflag.FlagSet
 	matcherFns    []func() ([]matcher.Matcher, error)
 	notMatcherFns []func() ([]matcher.Matcher, error)
+	terms         map[string]func(string) (matcher.Matcher, error)
 }
 
 // NewMatcherFlags returns a new FlagSet bound to the provided pflag.FlagSet.
 func NewMatcherFlags(flags *pflag.FlagSet) *FlagSet {
 	return &FlagSet{
 		flags: flags,
+		terms: make(map[string]func(string) (matcher.Matcher, error)),
 	}
 }
 
@@ -87,6 +90,14 @@ func (m *FlagSet) BoolMatcher(callback func() matcher.Matcher, name string, usag
 	}
 
 	m.add(name, fn)
+
+	m.addTerm(name, func(value string) (matcher.Matcher, error) {
+		if value != "" {
+			return nil, fmt.Errorf("term %q does not take a value", name)
+		}
+
+		return callback(), nil
+	})
 }
 
 // StringMatcher creates a named string flag paired with the given
@@ -108,6 +119,7 @@ func (m *FlagSet) StringMatcher(callback func(string) (matcher.Matcher, error),
 	}
 
 	m.add(name, fn)
+	m.addTerm(name, callback)
 }
 
 // StringSliceMatcher creates a named string slice flag paired with the
@@ -134,6 +146,29 @@ func (m *FlagSet) StringSliceMatcher(callback func(string) (matcher.Matcher, err
 		return matchers, nil
 	}
 
+	m.add(name, fn)
+	m.addTerm(name, callback)
+}
+
+// ExpressionMatcher creates a named string flag which accepts a boolean filter
+// expression. Terms within the expression can reference any other matcher
+// flag defined on this FlagSet by name, like "kind:deploy" or "label:app=web".
+func (m *FlagSet) ExpressionMatcher(name string, shorthand string, usage string) {
+	result := m.flags.StringP(name, shorthand, "", usage)
+
+	fn := func() ([]matcher.Matcher, error) {
+		if *result == "" {
+			return nil, nil
+		}
+
+		mm, err := matcher.ParseExpression(*result, m.term)
+		if err != nil {
+			return nil, err
+		}
+
+		return []matcher.Matcher{mm}, nil
+	}
+
 	m.add(name, fn)
 }
 
@@ -144,3 +179,24 @@ func (m *FlagSet) add(name string, fn func() ([]matcher.Matcher, error)) {
 		m.matcherFns = append(m.matcherFns, fn)
 	}
 }
+
+// addTerm registers the given matcher.Matcher constructor so that it can be
+// referenced by name from within a filter expression. Negative matcher names
+// are not registered, as the "not" operator can be used instead.
+func (m *FlagSet) addTerm(name string, callback func(string) (matcher.Matcher, error)) {
+	if strings.HasPrefix(name, "not-") {
+		return
+	}
+
+	m.terms[name] = callback
+}
+
+// term resolves a single filter expression term into a matcher.Matcher.
+func (m *FlagSet) term(name string, value string) (matcher.Matcher, error) {
+	callback, found := m.terms[name]
+	if !found {
+		return nil, fmt.Errorf("unknown term %q", name)
+	}
+
+	return callback(value)
+}
@@ -21,6 +21,42 @@ import (
 // Configuration represents the contents of a krf configuration file.
 type Configuration struct {
 	Resources []resolver.Resource `yaml:"resources"`
+
+	// Diff configures how resources are compared when using --diff.
+	Diff Diff `yaml:"diff"`
+
+	// Walk configures how directories are walked.
+	Walk Walk `yaml:"walk"`
+}
+
+// Diff represents the diff section of a krf configuration file, which can be
+// used to share normalization rules for comparing resources.
+type Diff struct {
+	// Ignore is a list of JSON paths for fields that are ignored.
+	Ignore []string `yaml:"ignore"`
+
+	// IgnoreCEL is a list of CEL rules for changes that are ignored.
+	IgnoreCEL []string `yaml:"ignoreCEL"`
+}
+
+// Walk represents the walk section of a krf configuration file, which can be
+// used to configure which files are decoded when walking directories.
+type Walk struct {
+	// Include is a list of globs for the files that are decoded.
+	Include []string `yaml:"include"`
+
+	// Exclude is a list of globs for the files and directories that are
+	// skipped.
+	Exclude []string `yaml:"exclude"`
+
+	// NoIgnoreFiles disables reading .gitignore and .krfignore files.
+	NoIgnoreFiles bool `yaml:"noIgnoreFiles"`
+
+	// FollowSymlinks configures symlinked directories to be walked.
+	FollowSymlinks bool `yaml:"followSymlinks"`
+
+	// MaxDepth limits how many directories deep are walked.
+	MaxDepth int `yaml:"maxDepth"`
 }
 
 //go:embed files/configuration.yaml
@@ -0,0 +1,213 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+// Package diff provides functions for pairing resources with their original
+// counterparts, and describing how they differ.
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+)
+
+// Status describes how a resource (or a single field within a resource)
+// differs from its original counterpart.
+type Status string
+
+const (
+	// Added indicates that there is no original counterpart.
+	Added Status = "added"
+
+	// Removed indicates that there is only an original counterpart.
+	Removed Status = "removed"
+
+	// Changed indicates that the original counterpart has different contents.
+	Changed Status = "changed"
+
+	// Unchanged indicates that the original counterpart has identical
+	// contents.
+	Unchanged Status = "unchanged"
+)
+
+// ParseStatus returns the Status with the given name.
+func ParseStatus(name string) (Status, error) {
+	switch status := Status(name); status {
+	case Added, Removed, Changed, Unchanged:
+		return status, nil
+	default:
+		return "", fmt.Errorf("unknown diff status: %s", name)
+	}
+}
+
+// Change is a single field that differs between two resources.
+type Change struct {
+	// Path is the JSONPath of the field, like ".spec.replicas".
+	Path string
+
+	// Type describes how the field differs. Never Unchanged.
+	Type Status
+
+	// Before is the original value of the field. Nil for Added changes.
+	Before any
+
+	// After is the modified value of the field. Nil for Removed changes.
+	After any
+}
+
+// Options configures how resources are paired with, and then compared
+// against, their original counterparts.
+type Options struct {
+	// Identity determines which original resource is the counterpart of a
+	// modified resource.
+	Identity Identity
+
+	// Ignore describes differences that should be ignored.
+	Ignore Ignore
+}
+
+// Changes returns every field that differs between the given original and
+// modified objects, excluding any ignored differences.
+func (o Options) Changes(before, after map[string]any) []Change {
+	changes := Fields(o.Ignore.Normalize(before), o.Ignore.Normalize(after))
+
+	return slices.DeleteFunc(changes, o.Ignore.Ignored)
+}
+
+// Fields returns every field that differs between the given original and
+// modified objects, sorted by path. Fields nested inside of an added or
+// removed field are not listed individually.
+func Fields(before, after map[string]any) []Change {
+	var changes []Change
+
+	walk("", before, after, &changes)
+
+	slices.SortStableFunc(changes, func(a, b Change) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return changes
+}
+
+// walk recursively compares the given values, which are located at the given
+// path, and records any differences.
+func walk(path string, before, after any, changes *[]Change) {
+	switch beforeValue := before.(type) {
+	case map[string]any:
+		afterValue, ok := after.(map[string]any)
+		if !ok {
+			break
+		}
+
+		for key, value := range beforeValue {
+			if _, found := afterValue[key]; !found {
+				*changes = append(*changes, Change{Path: fieldPath(path, key), Type: Removed, Before: value})
+			}
+		}
+
+		for key, value := range afterValue {
+			if previous, found := beforeValue[key]; found {
+				walk(fieldPath(path, key), previous, value, changes)
+			} else {
+				*changes = append(*changes, Change{Path: fieldPath(path, key), Type: Added, After: value})
+			}
+		}
+
+		return
+
+	case []any:
+		afterValue, ok := after.([]any)
+		if !ok {
+			break
+		}
+
+		// List items are compared by index, with any trailing items being
+		// either added or removed.
+		for i := range max(len(beforeValue), len(afterValue)) {
+			switch {
+			case i >= len(afterValue):
+				*changes = append(*changes, Change{Path: indexPath(path, i), Type: Removed, Before: beforeValue[i]})
+			case i >= len(beforeValue):
+				*changes = append(*changes, Change{Path: indexPath(path, i), Type: Added, After: afterValue[i]})
+			default:
+				walk(indexPath(path, i), beforeValue[i], afterValue[i], changes)
+			}
+		}
+
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{Path: path, Type: Changed, Before: before, After: after})
+	}
+}
+
+// simpleKey matches map keys that can be used in a JSONPath without quoting.
+var simpleKey = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// fieldPath returns the JSONPath of the given key within the given path.
+func fieldPath(path, key string) string {
+	if simpleKey.MatchString(key) {
+		return path + "." + key
+	}
+
+	return fmt.Sprintf("%s['%s']", path, key)
+}
+
+// indexPath returns the JSONPath of the given list index within the given
+// path.
+func indexPath(path string, index int) string {
+	return fmt.Sprintf("%s[%d]", path, index)
+}
+
+// Unified returns a unified diff of the given original and modified objects,
+// after they have been rendered as yaml. Either object may be nil, in which
+// case every line of the other is considered added or removed.
+func Unified(before, after map[string]any, fromName, toName string) (string, error) {
+	var beforeLines, afterLines []string
+
+	if before != nil {
+		data, err := yaml.Marshal(before)
+		if err != nil {
+			return "", err
+		}
+
+		beforeLines = splitLines(string(data))
+	}
+
+	if after != nil {
+		data, err := yaml.Marshal(after)
+		if err != nil {
+			return "", err
+		}
+
+		afterLines = splitLines(string(data))
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        beforeLines,
+		B:        afterLines,
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+}
+
+// splitLines splits the given text into lines, retaining each line ending.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+
+	// Text ending in a newline results in a trailing empty line.
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
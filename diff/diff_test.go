@@ -0,0 +1,89 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/joshdk/krf/diff"
+)
+
+func TestFields(t *testing.T) {
+	t.Parallel()
+
+	before := map[string]any{
+		"metadata": map[string]any{
+			"name": "backend",
+			"annotations": map[string]any{
+				"example.com/owner": "team-a",
+			},
+		},
+		"spec": map[string]any{
+			"replicas": int64(2),
+			"ports":    []any{int64(80), int64(443)},
+			"paused":   true,
+		},
+	}
+
+	after := map[string]any{
+		"metadata": map[string]any{
+			"name": "backend",
+			"annotations": map[string]any{
+				"example.com/owner": "team-b",
+			},
+			"labels": map[string]any{
+				"tier": "web",
+			},
+		},
+		"spec": map[string]any{
+			"replicas": int64(3),
+			"ports":    []any{int64(80)},
+		},
+	}
+
+	expected := []diff.Change{
+		{Path: ".metadata.annotations['example.com/owner']", Type: diff.Changed, Before: "team-a", After: "team-b"},
+		{Path: ".metadata.labels", Type: diff.Added, After: map[string]any{"tier": "web"}},
+		{Path: ".spec.paused", Type: diff.Removed, Before: true},
+		{Path: ".spec.ports[1]", Type: diff.Removed, Before: int64(443)},
+		{Path: ".spec.replicas", Type: diff.Changed, Before: int64(2), After: int64(3)},
+	}
+
+	if actual := diff.Fields(before, after); !cmp.Equal(expected, actual) {
+		t.Errorf("unexpected changes:\n%s", cmp.Diff(expected, actual))
+	}
+
+	if actual := diff.Fields(before, before); len(actual) != 0 {
+		t.Errorf("expected no changes but found %d", len(actual))
+	}
+}
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+
+	before := map[string]any{"kind": "ConfigMap", "data": map[string]any{"a": "1"}}
+	after := map[string]any{"kind": "ConfigMap", "data": map[string]any{"a": "2"}}
+
+	expected := `--- original.yaml
++++ modified.yaml
+@@ -1,3 +1,3 @@
+ data:
+-  a: "1"
++  a: "2"
+ kind: ConfigMap
+`
+
+	actual, err := diff.Unified(before, after, "original.yaml", "modified.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual != expected {
+		t.Errorf("unexpected diff:\n%s", cmp.Diff(expected, actual))
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// hashSuffix matches the hash suffix that kustomize appends to the names of
+// generated ConfigMaps and Secrets, like the "-8mbdf7882g" in
+// "credentials-8mbdf7882g". Kustomize encodes the hash using hex digits, with
+// the characters 0, 1, 3, a, and e replaced by g, h, k, m, and t.
+var hashSuffix = regexp.MustCompile(`-[bcdfghkmt2456789]{10}$`)
+
+// Identity determines which original resource is the counterpart of a
+// modified resource. The zero value pairs resources that have exactly the
+// same apiVersion, kind, namespace, and name.
+type Identity struct {
+	// IgnoreAPIVersion pairs resources regardless of their apiVersion, so
+	// that migrations like extensions/v1beta1 to apps/v1 are not treated as
+	// a removal and an addition.
+	IgnoreAPIVersion bool
+
+	// IgnoreHashSuffix pairs resources regardless of any kustomize generated
+	// hash suffix in their names.
+	IgnoreHashSuffix bool
+
+	// IgnoreNamespace pairs resources regardless of their namespace.
+	IgnoreNamespace bool
+
+	// NamePrefixes and NameSuffixes pair resources regardless of any of the
+	// given prefixes or suffixes in their names, like those added by a
+	// kustomize namePrefix or nameSuffix.
+	NamePrefixes []string
+	NameSuffixes []string
+
+	// Renames maps the names of original resources to the names of their
+	// modified counterparts.
+	Renames map[string]string
+}
+
+// ParseIdentity returns an Identity from the given list of strategies (any of
+// "api-version", "hash-suffix", "namespace", "name-prefix=<prefix>", or
+// "name-suffix=<suffix>") and renames.
+func ParseIdentity(strategies []string, renames map[string]string) (Identity, error) {
+	identity := Identity{Renames: renames}
+
+	for _, strategy := range strategies {
+		name, value, _ := strings.Cut(strategy, "=")
+
+		switch {
+		case name == "name-prefix" && value != "":
+			identity.NamePrefixes = append(identity.NamePrefixes, value)
+		case name == "name-suffix" && value != "":
+			identity.NameSuffixes = append(identity.NameSuffixes, value)
+		case strategy == "api-version":
+			identity.IgnoreAPIVersion = true
+		case strategy == "hash-suffix":
+			identity.IgnoreHashSuffix = true
+		case strategy == "namespace":
+			identity.IgnoreNamespace = true
+		default:
+			return Identity{}, fmt.Errorf("unknown diff identity strategy: %s", strategy)
+		}
+	}
+
+	return identity, nil
+}
+
+// Same reports if the given modified resource is the counterpart of the given
+// original resource.
+func (i Identity) Same(original, modified unstructured.Unstructured) bool {
+	return i.OriginalKey(original) == i.Key(modified)
+}
+
+// Key returns a key for the given modified resource, which is equal to the
+// OriginalKey of its original counterpart. Keys can be used to pair many
+// resources, without comparing every original against every modified
+// resource.
+func (i Identity) Key(modified unstructured.Unstructured) string {
+	return i.key(modified, modified.GetName())
+}
+
+// OriginalKey returns a key for the given original resource, which is equal
+// to the Key of its modified counterpart.
+func (i Identity) OriginalKey(original unstructured.Unstructured) string {
+	// Renames can be given using either the full original name, or the name
+	// with any ignored parts removed.
+	name := original.GetName()
+	if renamed, found := i.Renames[name]; found {
+		name = renamed
+	} else if renamed, found := i.Renames[i.name(name)]; found {
+		name = renamed
+	}
+
+	return i.key(original, name)
+}
+
+// key returns a key for the given resource with the given name, which omits
+// any parts that are being ignored.
+func (i Identity) key(item unstructured.Unstructured, name string) string {
+	apiVersion, namespace := item.GetAPIVersion(), item.GetNamespace()
+
+	if i.IgnoreAPIVersion {
+		apiVersion = ""
+	}
+
+	if i.IgnoreNamespace {
+		namespace = ""
+	}
+
+	return strings.Join([]string{apiVersion, item.GetKind(), namespace, i.name(name)}, "\x00")
+}
+
+// name returns the given resource name, with any parts that are being ignored
+// removed. Parts are removed in the reverse order that kustomize adds them,
+// which appends any hash suffix last.
+func (i Identity) name(name string) string {
+	if i.IgnoreHashSuffix {
+		name = hashSuffix.ReplaceAllString(name, "")
+	}
+
+	for _, suffix := range i.NameSuffixes {
+		name = strings.TrimSuffix(name, suffix)
+	}
+
+	for _, prefix := range i.NamePrefixes {
+		name = strings.TrimPrefix(name, prefix)
+	}
+
+	return name
+}
@@ -0,0 +1,149 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package diff_test
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/joshdk/krf/diff"
+)
+
+func TestIdentity(t *testing.T) {
+	t.Parallel()
+
+	resource := func(apiVersion, kind, namespace, name string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+			},
+		}}
+	}
+
+	tests := []struct {
+		title    string
+		identity diff.Identity
+		original unstructured.Unstructured
+		modified unstructured.Unstructured
+		expected bool
+	}{
+		{
+			title:    "exact",
+			original: resource("apps/v1", "Deployment", "default", "backend"),
+			modified: resource("apps/v1", "Deployment", "default", "backend"),
+			expected: true,
+		},
+		{
+			title:    "different kind",
+			identity: diff.Identity{IgnoreAPIVersion: true, IgnoreNamespace: true},
+			original: resource("v1", "ConfigMap", "default", "backend"),
+			modified: resource("v1", "Secret", "default", "backend"),
+			expected: false,
+		},
+		{
+			title:    "api version",
+			original: resource("extensions/v1beta1", "Deployment", "default", "backend"),
+			modified: resource("apps/v1", "Deployment", "default", "backend"),
+			expected: false,
+		},
+		{
+			title:    "ignore api version",
+			identity: diff.Identity{IgnoreAPIVersion: true},
+			original: resource("extensions/v1beta1", "Deployment", "default", "backend"),
+			modified: resource("apps/v1", "Deployment", "default", "backend"),
+			expected: true,
+		},
+		{
+			title:    "hash suffix",
+			original: resource("v1", "ConfigMap", "default", "credentials-8mbdf7882g"),
+			modified: resource("v1", "ConfigMap", "default", "credentials-ck5fk26hc4"),
+			expected: false,
+		},
+		{
+			title:    "ignore hash suffix",
+			identity: diff.Identity{IgnoreHashSuffix: true},
+			original: resource("v1", "ConfigMap", "default", "credentials-8mbdf7882g"),
+			modified: resource("v1", "ConfigMap", "default", "credentials-ck5fk26hc4"),
+			expected: true,
+		},
+		{
+			title:    "ignore hash suffix on different name",
+			identity: diff.Identity{IgnoreHashSuffix: true},
+			original: resource("v1", "ConfigMap", "default", "credentials-8mbdf7882g"),
+			modified: resource("v1", "ConfigMap", "default", "settings-ck5fk26hc4"),
+			expected: false,
+		},
+		{
+			title:    "not a hash suffix",
+			identity: diff.Identity{IgnoreHashSuffix: true},
+			original: resource("v1", "ConfigMap", "default", "credentials-3mbdf7882g"),
+			modified: resource("v1", "ConfigMap", "default", "credentials-ck5fk26hc4"),
+			expected: false,
+		},
+		{
+			title:    "ignore namespace",
+			identity: diff.Identity{IgnoreNamespace: true},
+			original: resource("apps/v1", "Deployment", "default", "backend"),
+			modified: resource("apps/v1", "Deployment", "production", "backend"),
+			expected: true,
+		},
+		{
+			title:    "renamed",
+			identity: diff.Identity{Renames: map[string]string{"backend": "api"}},
+			original: resource("apps/v1", "Deployment", "default", "backend"),
+			modified: resource("apps/v1", "Deployment", "default", "api"),
+			expected: true,
+		},
+		{
+			title:    "renamed with hash suffix",
+			identity: diff.Identity{IgnoreHashSuffix: true, Renames: map[string]string{"credentials-8mbdf7882g": "secrets"}},
+			original: resource("v1", "ConfigMap", "default", "credentials-8mbdf7882g"),
+			modified: resource("v1", "ConfigMap", "default", "secrets-ck5fk26hc4"),
+			expected: true,
+		},
+		{
+			title:    "renamed without hash suffix",
+			identity: diff.Identity{IgnoreHashSuffix: true, Renames: map[string]string{"credentials": "secrets"}},
+			original: resource("v1", "ConfigMap", "default", "credentials-8mbdf7882g"),
+			modified: resource("v1", "ConfigMap", "default", "secrets-ck5fk26hc4"),
+			expected: true,
+		},
+		{
+			title:    "name prefix",
+			original: resource("apps/v1", "Deployment", "default", "backend"),
+			modified: resource("apps/v1", "Deployment", "default", "prod-backend"),
+			expected: false,
+		},
+		{
+			title:    "ignore name prefix",
+			identity: diff.Identity{NamePrefixes: []string{"prod-"}},
+			original: resource("apps/v1", "Deployment", "default", "backend"),
+			modified: resource("apps/v1", "Deployment", "default", "prod-backend"),
+			expected: true,
+		},
+		{
+			title:    "ignore name prefix and suffix with hash suffix",
+			identity: diff.Identity{IgnoreHashSuffix: true, NamePrefixes: []string{"prod-", "stage-"}, NameSuffixes: []string{"-v2"}},
+			original: resource("v1", "ConfigMap", "default", "stage-credentials-8mbdf7882g"),
+			modified: resource("v1", "ConfigMap", "default", "prod-credentials-v2-ck5fk26hc4"),
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := test.identity.Same(test.original, test.modified); actual != test.expected {
+				t.Errorf("expected %t but got %t", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+// Ignore describes differences between resources that should be ignored,
+// like annotations that are updated on every rollout, or fields that are
+// defaulted by a live cluster. The zero value ignores nothing.
+type Ignore struct {
+	paths []fieldPattern
+	rules []cel.Program
+}
+
+// ParseIgnore returns an Ignore from the given lists of JSON paths and CEL
+// rules.
+//
+// Fields located at any of the given JSON paths (like
+// ".metadata.annotations['kubectl.kubernetes.io/restartedAt']") are removed
+// from both resources before they are compared. A "*" can be used in place of
+// a field name or list index to match any field or list item.
+//
+// Each of the given CEL rules is evaluated against every individual change
+// between two resources, with the variables "path" (the JSONPath of the
+// field), "before", and "after" (the original and modified values). Changes
+// for which any rule evaluates to true are ignored. For example:
+//
+//	path.endsWith('.image') && before.split('@')[0] == after.split('@')[0]
+func ParseIgnore(paths []string, rules []string) (Ignore, error) {
+	var ignore Ignore
+
+	for _, path := range paths {
+		pattern, err := parseFieldPattern(path)
+		if err != nil {
+			return Ignore{}, err
+		}
+
+		ignore.paths = append(ignore.paths, pattern)
+	}
+
+	if len(rules) == 0 {
+		return ignore, nil
+	}
+
+	// Prepare the CEL environment to expect the variables describing a single
+	// change.
+	env, err := cel.NewEnv(
+		cel.Variable("path", cel.StringType),
+		cel.Variable("before", cel.DynType),
+		cel.Variable("after", cel.DynType),
+		ext.Strings(),
+	)
+	if err != nil {
+		return Ignore{}, err
+	}
+
+	for _, rule := range rules {
+		ast, iss := env.Compile(rule)
+		if iss.Err() != nil {
+			return Ignore{}, iss.Err()
+		}
+
+		if !ast.OutputType().IsExactType(types.BoolType) {
+			return Ignore{}, errors.New("cel rule does not have a bool output type")
+		}
+
+		program, err := env.Program(ast)
+		if err != nil {
+			return Ignore{}, err
+		}
+
+		ignore.rules = append(ignore.rules, program)
+	}
+
+	return ignore, nil
+}
+
+// Normalize returns a copy of the given object, with every field matching one
+// of the ignored JSON paths removed. Maps and lists that are left empty as a
+// result are also removed.
+func (i Ignore) Normalize(object map[string]any) map[string]any {
+	if len(i.paths) == 0 || object == nil {
+		return object
+	}
+
+	result, _ := deepCopy(object).(map[string]any)
+
+	for _, pattern := range i.paths {
+		removeFields(result, pattern)
+	}
+
+	return result
+}
+
+// Ignored reports if the given change is ignored by any of the CEL rules.
+func (i Ignore) Ignored(change Change) bool {
+	for _, program := range i.rules {
+		result, _, err := program.Eval(map[string]any{
+			"path":   change.Path,
+			"before": change.Before,
+			"after":  change.After,
+		})
+		if err != nil {
+			continue
+		}
+
+		if boolResult, ok := result.Value().(bool); ok && boolResult {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldSegment is a single segment of a fieldPattern, which is either a map
+// key, a list index, or a wildcard matching either.
+type fieldSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// fieldPattern is a parsed JSON path, like ".spec.containers[*].image".
+type fieldPattern []fieldSegment
+
+// parseFieldPattern parses the given JSON path. Paths can optionally be
+// wrapped in braces, and start with a "$", as with kubectl.
+func parseFieldPattern(path string) (fieldPattern, error) {
+	original := path
+
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "{"), "}")
+	path = strings.TrimPrefix(path, "$")
+
+	var pattern fieldPattern
+
+	for path != "" {
+		switch {
+		case path[0] == '.':
+			end := strings.IndexAny(path[1:], ".[")
+			if end < 0 {
+				end = len(path) - 1
+			}
+
+			name := path[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("invalid json path %q: empty field name", original)
+			}
+
+			pattern = append(pattern, fieldSegment{key: name, wildcard: name == "*"})
+			path = path[end+1:]
+
+		case path[0] == '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: unterminated bracket", original)
+			}
+
+			inner := path[1:end]
+
+			switch {
+			case inner == "*":
+				pattern = append(pattern, fieldSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				pattern = append(pattern, fieldSegment{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid json path %q: invalid index %q", original, inner)
+				}
+
+				pattern = append(pattern, fieldSegment{index: index, isIndex: true})
+			}
+
+			path = path[end+1:]
+
+		default:
+			return nil, fmt.Errorf("invalid json path %q: expected '.' or '['", original)
+		}
+	}
+
+	if len(pattern) == 0 {
+		return nil, fmt.Errorf("invalid json path %q: empty path", original)
+	}
+
+	return pattern, nil
+}
+
+// removeFields removes every field matching the given pattern from the given
+// value, and returns the (possibly replaced) value along with whether
+// anything was removed.
+func removeFields(value any, pattern fieldPattern) (any, bool) {
+	if len(pattern) == 0 {
+		return value, false
+	}
+
+	segment, last := pattern[0], len(pattern) == 1
+
+	switch v := value.(type) {
+	case map[string]any:
+		var removed bool
+
+		for key, child := range v {
+			if segment.isIndex || (!segment.wildcard && segment.key != key) {
+				continue
+			}
+
+			if last {
+				delete(v, key)
+
+				removed = true
+
+				continue
+			}
+
+			if child, childRemoved := removeFields(child, pattern[1:]); childRemoved {
+				removed = true
+
+				// Remove any maps or lists that are now empty.
+				if isEmpty(child) {
+					delete(v, key)
+				} else {
+					v[key] = child
+				}
+			}
+		}
+
+		return v, removed
+
+	case []any:
+		var (
+			removed bool
+			results = make([]any, 0, len(v))
+		)
+
+		for i, child := range v {
+			if !segment.wildcard && (!segment.isIndex || segment.index != i) {
+				results = append(results, child)
+
+				continue
+			}
+
+			if last {
+				removed = true
+
+				continue
+			}
+
+			child, childRemoved := removeFields(child, pattern[1:])
+			if childRemoved {
+				removed = true
+
+				// Remove any maps or lists that are now empty.
+				if isEmpty(child) {
+					continue
+				}
+			}
+
+			results = append(results, child)
+		}
+
+		return results, removed
+
+	default:
+		return value, false
+	}
+}
+
+// isEmpty reports if the given value is an empty map or list.
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// deepCopy returns a deep copy of the given value, which is made up of maps,
+// lists, and scalar values.
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, child := range v {
+			result[key] = deepCopy(child)
+		}
+
+		return result
+
+	case []any:
+		result := make([]any, len(v))
+		for i, child := range v {
+			result[i] = deepCopy(child)
+		}
+
+		return result
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/joshdk/krf/diff"
+)
+
+func TestIgnoreNormalize(t *testing.T) {
+	t.Parallel()
+
+	object := func() map[string]any {
+		return map[string]any{
+			"metadata": map[string]any{
+				"name": "backend",
+				"annotations": map[string]any{
+					"kubectl.kubernetes.io/restartedAt": "2024-01-01T00:00:00Z",
+				},
+			},
+			"spec": map[string]any{
+				"containers": []any{
+					map[string]any{"name": "app", "image": "app@sha256:aaaa"},
+					map[string]any{"name": "sidecar", "image": "sidecar@sha256:bbbb"},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		title    string
+		paths    []string
+		expected map[string]any
+	}{
+		{
+			title:    "no paths",
+			expected: object(),
+		},
+		{
+			title: "quoted key",
+			paths: []string{`.metadata.annotations['kubectl.kubernetes.io/restartedAt']`},
+			expected: map[string]any{
+				"metadata": map[string]any{
+					"name": "backend",
+				},
+				"spec": object()["spec"],
+			},
+		},
+		{
+			title: "kubectl style",
+			paths: []string{`{$.metadata.annotations["kubectl.kubernetes.io/restartedAt"]}`},
+			expected: map[string]any{
+				"metadata": map[string]any{
+					"name": "backend",
+				},
+				"spec": object()["spec"],
+			},
+		},
+		{
+			title: "wildcard index",
+			paths: []string{".spec.containers[*].image"},
+			expected: map[string]any{
+				"metadata": object()["metadata"],
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": "app"},
+						map[string]any{"name": "sidecar"},
+					},
+				},
+			},
+		},
+		{
+			title: "single index",
+			paths: []string{".spec.containers[1]"},
+			expected: map[string]any{
+				"metadata": object()["metadata"],
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": "app", "image": "app@sha256:aaaa"},
+					},
+				},
+			},
+		},
+		{
+			title: "wildcard field",
+			paths: []string{".metadata.*"},
+			expected: map[string]any{
+				"spec": object()["spec"],
+			},
+		},
+		{
+			title:    "missing path",
+			paths:    []string{".status.replicas"},
+			expected: object(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			t.Parallel()
+
+			ignore, err := diff.ParseIgnore(test.paths, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			original := object()
+
+			if actual := ignore.Normalize(original); !cmp.Equal(test.expected, actual) {
+				t.Errorf("unexpected object:\n%s", cmp.Diff(test.expected, actual))
+			}
+
+			// The given object should never be modified.
+			if !cmp.Equal(object(), original) {
+				t.Errorf("original object was modified")
+			}
+		})
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	t.Parallel()
+
+	ignore, err := diff.ParseIgnore(nil, []string{
+		"path.endsWith('.image') && before.split('@')[0] == after.split('@')[0]",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := map[string]any{"image": "app@sha256:aaaa", "name": "app"}
+	after := map[string]any{"image": "app@sha256:bbbb", "name": "other"}
+
+	expected := []diff.Change{
+		{Path: ".name", Type: diff.Changed, Before: "app", After: "other"},
+	}
+
+	if actual := (diff.Options{Ignore: ignore}).Changes(before, after); !cmp.Equal(expected, actual) {
+		t.Errorf("unexpected changes:\n%s", cmp.Diff(expected, actual))
+	}
+}
+
+func TestParseIgnoreErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{"", "metadata", ".metadata..name", ".spec.containers[x]", ".spec.containers[0"} {
+		if _, err := diff.ParseIgnore([]string{path}, nil); err == nil {
+			t.Errorf("expected an error for path %q", path)
+		}
+	}
+
+	for _, rule := range []string{"path ==", "path"} {
+		if _, err := diff.ParseIgnore(nil, []string{rule}); err == nil {
+			t.Errorf("expected an error for rule %q", rule)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+// Package edit provides functions for modifying resources in place, for use
+// with resources.Edit.
+package edit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/utils"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/merge2"
+
+	"github.com/joshdk/krf/resources"
+)
+
+// Chain returns a resources.EditFunc which applies each of the given edits in
+// order.
+func Chain(edits ...resources.EditFunc) resources.EditFunc {
+	return func(node *yaml.RNode) error {
+		for _, edit := range edits {
+			if err := edit(node); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// Set returns a resources.EditFunc which sets the field at a Kustomize-style
+// fieldpath to a yaml value, given as an expression like ".spec.replicas=3".
+// Missing fields are created, and a value of "null" removes the field. Values
+// are parsed as yaml, so a value like "true" must be quoted to set a string,
+// while strings like "y" or "no" are quoted automatically so that they are
+// not read as booleans by YAML 1.1 parsers.
+func Set(expression string) (resources.EditFunc, error) {
+	path, value, found := splitExpression(expression)
+	if !found {
+		return nil, fmt.Errorf("invalid expression %q: expected a fieldpath and value like .spec.replicas=3", expression)
+	}
+
+	fields := splitFieldPath(strings.TrimPrefix(path, "."))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid expression %q: missing fieldpath", expression)
+	}
+
+	valueNode := yaml.NewStringRNode("")
+
+	if value != "" {
+		var err error
+		if valueNode, err = yaml.Parse(value); err != nil {
+			return nil, fmt.Errorf("invalid expression %q: %w", expression, err)
+		}
+	}
+
+	// Quote plain strings like "y" or "no", which would otherwise be read as
+	// booleans by YAML 1.1 parsers.
+	scalar := valueNode.YNode()
+	if scalar.Style == 0 && scalar.ShortTag() == yaml.NodeTagString && yaml.IsYaml1_1NonString(scalar) {
+		scalar.Style = yaml.DoubleQuotedStyle
+	}
+
+	if valueNode.IsTaggedNull() {
+		return func(node *yaml.RNode) error {
+			return remove(node, fields)
+		}, nil
+	}
+
+	return func(node *yaml.RNode) error {
+		target, err := node.Pipe(yaml.LookupCreate(valueNode.YNode().Kind, fields...))
+		if err != nil {
+			return err
+		}
+
+		if target == nil {
+			return fmt.Errorf("could not set field %q", path)
+		}
+
+		// Replace the target node with a copy of the value, while keeping any
+		// existing comments.
+		replacement := *valueNode.Copy().YNode()
+		replacement.HeadComment = target.YNode().HeadComment
+		replacement.LineComment = target.YNode().LineComment
+		replacement.FootComment = target.YNode().FootComment
+
+		target.SetYNode(&replacement)
+
+		return nil
+	}, nil
+}
+
+// remove removes the field at the given fieldpath, if it exists.
+func remove(node *yaml.RNode, fields []string) error {
+	target, err := node.Pipe(yaml.Lookup(fields...))
+	if err != nil || target == nil {
+		return err
+	}
+
+	parent, err := node.Pipe(yaml.Lookup(fields[:len(fields)-1]...))
+	if err != nil || parent == nil {
+		return err
+	}
+
+	content := parent.YNode().Content
+
+	index := slices.Index(content, target.YNode())
+	if index == -1 {
+		return nil
+	}
+
+	// Mapping nodes hold pairs of key and value nodes, so remove the key node
+	// as well.
+	if parent.YNode().Kind == yaml.MappingNode {
+		parent.YNode().Content = slices.Delete(content, index-1, index+1)
+	} else {
+		parent.YNode().Content = slices.Delete(content, index, index+1)
+	}
+
+	return nil
+}
+
+// PatchFile returns a resources.EditFunc which applies the strategic merge
+// patch contained in the given file. The patch identity (apiVersion, kind,
+// name, and namespace) is ignored, so that the same patch can be applied to
+// every resource.
+func PatchFile(filename string) (resources.EditFunc, error) {
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := yaml.Parse(string(contents))
+	if err != nil {
+		return nil, fmt.Errorf("parsing patch %s: %w", filename, err)
+	}
+
+	if patch.YNode().Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing patch %s: only strategic merge patches are supported", filename)
+	}
+
+	for _, field := range []string{"apiVersion", "kind"} {
+		if err := patch.PipeE(yaml.Clear(field)); err != nil {
+			return nil, err
+		}
+	}
+
+	if metadata := patch.Field(yaml.MetadataField); metadata != nil {
+		for _, field := range []string{yaml.NameField, yaml.NamespaceField} {
+			if err := metadata.Value.PipeE(yaml.Clear(field)); err != nil {
+				return nil, err
+			}
+		}
+
+		if fields, err := metadata.Value.Fields(); err == nil && len(fields) == 0 {
+			if err := patch.PipeE(yaml.Clear(yaml.MetadataField)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return func(node *yaml.RNode) error {
+		// Merging replaces any patched values, so record the existing comments
+		// in order to restore them afterward.
+		comments := make(map[string]yaml.Node)
+		walkComments(node.YNode(), "", func(path string, node *yaml.Node) {
+			comments[path] = *node
+		})
+
+		result, err := merge2.Merge(patch.Copy(), node, yaml.MergeOptions{})
+		if err != nil {
+			return err
+		}
+
+		if result == nil {
+			return errors.New("patch deletes the resource")
+		}
+
+		if result.YNode() != node.YNode() {
+			node.SetYNode(result.YNode())
+		}
+
+		walkComments(node.YNode(), "", func(path string, node *yaml.Node) {
+			original, found := comments[path]
+			if !found || node.HeadComment != "" || node.LineComment != "" || node.FootComment != "" {
+				return
+			}
+
+			node.HeadComment = original.HeadComment
+			node.LineComment = original.LineComment
+			node.FootComment = original.FootComment
+		})
+
+		return nil
+	}, nil
+}
+
+// walkComments calls the given function with every value node within the
+// given node, along with a path identifying that node. List elements with a
+// name are identified by that name, rather than by their index.
+func walkComments(node *yaml.Node, path string, fn func(string, *yaml.Node)) {
+	fn(path, node)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			walkComments(node.Content[index+1], path+"."+node.Content[index].Value, fn)
+		}
+
+	case yaml.SequenceNode:
+		for index, element := range node.Content {
+			key := fmt.Sprintf("[%d]", index)
+			if name := yaml.NewRNode(element).Field(yaml.NameField); element.Kind == yaml.MappingNode && name != nil {
+				key = "[name=" + name.Value.YNode().Value + "]"
+			}
+
+			walkComments(element, path+key, fn)
+		}
+	}
+}
+
+// splitExpression splits the given expression on the first '=' character that
+// is not within brackets, as fieldpaths like ".spec.containers.[name=main]"
+// can themselves contain '=' characters.
+func splitExpression(expression string) (string, string, bool) {
+	var depth int
+
+	for index, char := range expression {
+		switch char {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '=':
+			if depth == 0 {
+				return expression[:index], expression[index+1:], true
+			}
+		}
+	}
+
+	return expression, "", false
+}
+
+// splitFieldPath splits the given fieldpath in the same way as
+// yaml.RNode.GetFieldValue, where list indexes like "ports[0]" are split into
+// a separate "0" field.
+func splitFieldPath(path string) []string {
+	var fields []string
+
+	for _, field := range utils.SmarterPathSplitter(path, ".") {
+		if field == "" {
+			continue
+		}
+
+		if groups := sliceIndexPattern.FindStringSubmatch(field); groups != nil {
+			if groups[1] != "" {
+				fields = append(fields, groups[1])
+			}
+
+			fields = append(fields, groups[2])
+
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// sliceIndexPattern matches a fieldpath field with a trailing list index.
+var sliceIndexPattern = regexp.MustCompile(`^(.*)\[(\d+)\]$`)
@@ -0,0 +1,327 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package edit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/joshdk/krf/edit"
+)
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # the name
+  labels:
+    app: web
+spec:
+  # the number of replicas
+  replicas: 2 # default
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.25
+      - name: sidecar
+        image: envoy:1.30
+`
+
+func TestSet(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	tests := map[string]struct {
+		expression string
+		expected   string
+	}{
+		"replace scalar": {
+			expression: ".spec.replicas=3",
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # the name
+  labels:
+    app: web
+spec:
+  # the number of replicas
+  replicas: 3 # default
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.25
+      - name: sidecar
+        image: envoy:1.30
+`,
+		},
+
+		"create field": {
+			expression: "metadata.labels.tier=frontend",
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # the name
+  labels:
+    app: web
+    tier: frontend
+spec:
+  # the number of replicas
+  replicas: 2 # default
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.25
+      - name: sidecar
+        image: envoy:1.30
+`,
+		},
+
+		"list selector": {
+			expression: ".spec.template.spec.containers.[name=main].image=nginx:1.27",
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # the name
+  labels:
+    app: web
+spec:
+  # the number of replicas
+  replicas: 2 # default
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.27
+      - name: sidecar
+        image: envoy:1.30
+`,
+		},
+
+		"list index": {
+			expression: ".spec.template.spec.containers[1].image=envoy:1.31",
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # the name
+  labels:
+    app: web
+spec:
+  # the number of replicas
+  replicas: 2 # default
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.25
+      - name: sidecar
+        image: envoy:1.31
+`,
+		},
+
+		"mapping value": {
+			expression: ".metadata.annotations={owner: team-a}",
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # the name
+  labels:
+    app: web
+  annotations: {owner: team-a}
+spec:
+  # the number of replicas
+  replicas: 2 # default
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.25
+      - name: sidecar
+        image: envoy:1.30
+`,
+		},
+
+		"quote yaml 1.1 booleans": {
+			expression: ".metadata.labels.app=y",
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # the name
+  labels:
+    app: "y"
+spec:
+  # the number of replicas
+  replicas: 2 # default
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.25
+      - name: sidecar
+        image: envoy:1.30
+`,
+		},
+
+		"quote new yaml 1.1 booleans": {
+			expression: ".metadata.labels.enabled=no",
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # the name
+  labels:
+    app: web
+    enabled: "no"
+spec:
+  # the number of replicas
+  replicas: 2 # default
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.25
+      - name: sidecar
+        image: envoy:1.30
+`,
+		},
+
+		"remove field": {
+			expression: ".metadata.labels=null",
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # the name
+spec:
+  # the number of replicas
+  replicas: 2 # default
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.25
+      - name: sidecar
+        image: envoy:1.30
+`,
+		},
+
+		"remove list element": {
+			expression: ".spec.template.spec.containers.[name=sidecar]=null",
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # the name
+  labels:
+    app: web
+spec:
+  # the number of replicas
+  replicas: 2 # default
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.25
+`,
+		},
+
+		"remove missing field": {
+			expression: ".metadata.annotations.missing=null",
+			expected:   deployment,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			editFn, err := edit.Set(test.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			node := yaml.MustParse(deployment)
+
+			if err := editFn(node); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.expected, node.MustString()); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, expression := range []string{"", ".spec.replicas", "=3", ".spec.replicas=[1"} {
+		if _, err := edit.Set(expression); err == nil {
+			t.Errorf("expected an error for expression %q", expression)
+		}
+	}
+}
+
+func TestPatchFile(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "patch.yaml")
+
+	patch := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: other
+  labels:
+    tier: frontend
+spec:
+  replicas: 5
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.27
+`
+
+	if err := os.WriteFile(filename, []byte(patch), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	editFn, err := edit.PatchFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := yaml.MustParse(deployment)
+
+	if err := editFn(node); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # the name
+  labels:
+    app: web
+    tier: frontend
+spec:
+  # the number of replicas
+  replicas: 5 # default
+  template:
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.27
+      - name: sidecar
+        image: envoy:1.30
+`
+
+	if diff := cmp.Diff(expected, node.MustString()); diff != "" {
+		t.Errorf("unexpected result (-want +got):\n%s", diff)
+	}
+
+	if _, err := edit.PatchFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing patch file")
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TermFunc resolves a single named term (like "kind" with the value "deploy")
+// from a filter expression into a concrete Matcher.
+type TermFunc func(name string, value string) (Matcher, error)
+
+// ExpressionError describes a problem encountered while parsing a filter
+// expression, along with the (1-indexed) column where it happened.
+type ExpressionError struct {
+	Column  int
+	Message string
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("filter expression column %d: %s", e.Column, e.Message)
+}
+
+// ParseExpression compiles the given boolean filter expression into a tree of
+// AllMatcher, AnyMatcher, and NotMatcher instances. Each individual term in
+// the expression is resolved into a Matcher using the given TermFunc.
+//
+// Expressions are composed of terms, the operators "and", "or", and "not", and
+// parentheses for grouping. Operator precedence (from highest to lowest) is
+// "not", "and", then "or". Terms take the form "name:value" (or just "name"
+// for terms that take no value) and values containing whitespace or
+// parentheses can be quoted.
+//
+// For example:
+//
+//	(kind:deploy and label:tier=web) or (kind:svc and namespace:edge)
+//	not kind:secret and cel:"object.metadata.name.startsWith('app-')"
+func ParseExpression(expression string, termFn TermFunc) (Matcher, error) {
+	tokens, err := lexExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := expressionParser{
+		tokens: tokens,
+		termFn: termFn,
+	}
+
+	result, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	// The whole expression should have been consumed at this point.
+	if next := p.peek(); next.kind != tokenEnd {
+		return nil, &ExpressionError{Column: next.column, Message: fmt.Sprintf("unexpected %s", next)}
+	}
+
+	return result, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenLeftParen
+	tokenRightParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenTerm
+)
+
+// token is a single lexical element of a filter expression.
+type token struct {
+	kind   tokenKind
+	column int
+
+	// name and value are only set for tokenTerm tokens.
+	name  string
+	value string
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEnd:
+		return "end of expression"
+	case tokenLeftParen:
+		return `"("`
+	case tokenRightParen:
+		return `")"`
+	case tokenAnd:
+		return `"and"`
+	case tokenOr:
+		return `"or"`
+	case tokenNot:
+		return `"not"`
+	default:
+		return fmt.Sprintf("term %q", t.name)
+	}
+}
+
+// lexExpression splits the given expression into a list of tokens, always
+// terminated by a tokenEnd token.
+func lexExpression(expression string) ([]token, error) { //nolint:cyclop,funlen
+	var (
+		runes  = []rune(expression)
+		tokens []token
+	)
+
+	for i := 0; i < len(runes); {
+		column := i + 1
+
+		switch char := runes[i]; {
+		case unicode.IsSpace(char):
+			i++
+
+		case char == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, column: column})
+			i++
+
+		case char == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, column: column})
+			i++
+
+		default:
+			// Consume a bare word, which is either an operator keyword or the
+			// name of a term.
+			start := i
+			for i < len(runes) && isNameRune(runes[i]) {
+				i++
+			}
+
+			if start == i {
+				return nil, &ExpressionError{Column: column, Message: fmt.Sprintf("unexpected character %q", char)}
+			}
+
+			name := string(runes[start:i])
+
+			switch name {
+			case "and":
+				tokens = append(tokens, token{kind: tokenAnd, column: column})
+
+				continue
+			case "or":
+				tokens = append(tokens, token{kind: tokenOr, column: column})
+
+				continue
+			case "not":
+				tokens = append(tokens, token{kind: tokenNot, column: column})
+
+				continue
+			}
+
+			term := token{kind: tokenTerm, column: column, name: name}
+
+			// This term has no value, like "cluster-scoped".
+			if i >= len(runes) || runes[i] != ':' {
+				tokens = append(tokens, term)
+
+				continue
+			}
+
+			// Skip over the ':' separator.
+			i++
+
+			value, next, err := lexValue(runes, i)
+			if err != nil {
+				return nil, err
+			}
+
+			term.value = value
+			tokens = append(tokens, term)
+			i = next
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, column: len(runes) + 1}), nil
+}
+
+// lexValue consumes a single term value starting at the given index, and
+// returns the value along with the index immediately after it. Values are
+// either quoted (using single or double quotes) or bare, in which case they
+// extend until the next whitespace or parenthesis character.
+func lexValue(runes []rune, start int) (string, int, error) {
+	if start >= len(runes) {
+		return "", start, &ExpressionError{Column: start + 1, Message: "missing term value"}
+	}
+
+	// Consume a bare value.
+	if quote := runes[start]; quote != '"' && quote != '\'' {
+		i := start
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+			i++
+		}
+
+		if i == start {
+			return "", start, &ExpressionError{Column: start + 1, Message: "missing term value"}
+		}
+
+		return string(runes[start:i]), i, nil
+	}
+
+	// Consume a quoted value, where a '\' can be used to escape the quote
+	// character (or another '\').
+	var (
+		quote   = runes[start]
+		value   strings.Builder
+		escaped bool
+	)
+
+	for i := start + 1; i < len(runes); i++ {
+		switch char := runes[i]; {
+		case escaped:
+			value.WriteRune(char)
+
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == quote:
+			return value.String(), i + 1, nil
+		default:
+			value.WriteRune(char)
+		}
+	}
+
+	return "", start, &ExpressionError{Column: start + 1, Message: "unterminated quoted value"}
+}
+
+// isNameRune reports if the given rune can be used in an operator keyword or
+// a term name.
+func isNameRune(char rune) bool {
+	return unicode.IsLetter(char) || unicode.IsDigit(char) || char == '-' || char == '_'
+}
+
+// expressionParser is a recursive descent parser over a list of tokens.
+type expressionParser struct {
+	tokens []token
+	index  int
+	termFn TermFunc
+}
+
+func (p *expressionParser) peek() token {
+	return p.tokens[p.index]
+}
+
+func (p *expressionParser) next() token {
+	current := p.tokens[p.index]
+	if current.kind != tokenEnd {
+		p.index++
+	}
+
+	return current
+}
+
+// parseOr parses a sequence of one or more and-expressions joined by "or".
+func (p *expressionParser) parseOr() (Matcher, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != tokenOr {
+		return first, nil
+	}
+
+	chain := &AnyMatcher{}
+	chain.Append(first)
+
+	for p.peek().kind == tokenOr {
+		p.next()
+
+		mm, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		chain.Append(mm)
+	}
+
+	return chain, nil
+}
+
+// parseAnd parses a sequence of one or more unary-expressions joined by "and".
+func (p *expressionParser) parseAnd() (Matcher, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != tokenAnd {
+		return first, nil
+	}
+
+	chain := &AllMatcher{}
+	chain.Append(first)
+
+	for p.peek().kind == tokenAnd {
+		p.next()
+
+		mm, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		chain.Append(mm)
+	}
+
+	return chain, nil
+}
+
+// parseUnary parses a (possibly negated) parenthesized expression or term.
+func (p *expressionParser) parseUnary() (Matcher, error) {
+	switch current := p.next(); current.kind {
+	case tokenNot:
+		mm, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return NotMatcher(mm), nil
+
+	case tokenLeftParen:
+		mm, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.next(); closing.kind != tokenRightParen {
+			return nil, &ExpressionError{Column: closing.column, Message: fmt.Sprintf("expected \")\" but found %s", closing)}
+		}
+
+		return mm, nil
+
+	case tokenTerm:
+		mm, err := p.termFn(current.name, current.value)
+		if err != nil {
+			return nil, &ExpressionError{Column: current.column, Message: err.Error()}
+		}
+
+		return mm, nil
+
+	default:
+		return nil, &ExpressionError{Column: current.column, Message: fmt.Sprintf("expected term but found %s", current)}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/joshdk/krf/matcher"
+)
+
+// testTerm resolves filter expression terms using a small subset of the
+// available matchers.
+func testTerm(name string, value string) (matcher.Matcher, error) {
+	switch name {
+	case "cel":
+		return matcher.NewCELMatcher(value)
+	case "kind":
+		return matcher.NewKindMatcher(value)
+	case "label":
+		return matcher.NewLabelMatcher(value)
+	case "name":
+		return matcher.NewNameMatcher(value)
+	case "namespace":
+		return matcher.NewNamespaceMatcher(value)
+	case "cluster-scoped":
+		return matcher.NewClusterScopedMatcher(), nil
+	default:
+		return nil, fmt.Errorf("unknown term %q", name)
+	}
+}
+
+func TestExpressionMatcher(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	testMatcher(t, []spec{
+		{
+			title:   "single term",
+			matcher: must(matcher.ParseExpression("kind:deploy", testTerm)),
+			matches: []string{
+				"Deployment/nginx-deployment",
+			},
+		},
+		{
+			title:   "term without value",
+			matcher: must(matcher.ParseExpression("cluster-scoped", testTerm)),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+			},
+		},
+		{
+			title:   "and",
+			matcher: must(matcher.ParseExpression("kind:svc and label:app=myapp", testTerm)),
+			matches: []string{
+				"Service/my-service",
+			},
+		},
+		{
+			title:   "or",
+			matcher: must(matcher.ParseExpression("kind:svc or kind:cm", testTerm)),
+			matches: []string{
+				"ConfigMap/my-configmap",
+				"Service/my-service",
+			},
+		},
+		{
+			title:   "not",
+			matcher: must(matcher.ParseExpression("not namespace:custom-app*", testTerm)),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+				"Deployment/nginx-deployment",
+			},
+		},
+		{
+			title:   "precedence",
+			matcher: must(matcher.ParseExpression("kind:po or kind:deploy and label:app=nginx or kind:svc and label:app=other", testTerm)),
+			matches: []string{
+				"Deployment/nginx-deployment",
+				"Pod/test-pod",
+			},
+		},
+		{
+			title:   "parentheses",
+			matcher: must(matcher.ParseExpression("(kind:deploy and label:component=proxy) or (kind:svc and namespace:custom-app)", testTerm)),
+			matches: []string{
+				"Deployment/nginx-deployment",
+				"Service/my-service",
+			},
+		},
+		{
+			title:   "nested not",
+			matcher: must(matcher.ParseExpression("not (kind:svc or not namespace:custom-app)", testTerm)),
+			matches: []string{
+				"ConfigMap/my-configmap",
+			},
+		},
+		{
+			title:   "quoted value",
+			matcher: must(matcher.ParseExpression(`cel:"object.kind == 'Pod' && object.spec.restartPolicy == 'Never'"`, testTerm)),
+			matches: []string{
+				"Pod/test-pod",
+			},
+		},
+		{
+			title:   "single quoted value with escapes",
+			matcher: must(matcher.ParseExpression(`cel:'object.metadata.name == \'my-service\''`, testTerm)),
+			matches: []string{
+				"Service/my-service",
+			},
+		},
+	})
+}
+
+func TestExpressionErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		expression string
+		column     int
+	}{
+		"empty":                {expression: "", column: 1},
+		"unknown term":         {expression: "kind:svc and color:red", column: 14},
+		"invalid term value":   {expression: "not kind:[", column: 5},
+		"missing value":        {expression: "kind:", column: 6},
+		"missing operand":      {expression: "kind:svc and", column: 13},
+		"missing operator":     {expression: "kind:svc kind:cm", column: 10},
+		"unbalanced open":      {expression: "(kind:svc or kind:cm", column: 21},
+		"unbalanced close":     {expression: "kind:svc)", column: 9},
+		"unterminated quote":   {expression: `kind:svc or cel:"object`, column: 17},
+		"unexpected character": {expression: "kind:svc & kind:cm", column: 10},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := matcher.ParseExpression(test.expression, testTerm)
+
+			var exprErr *matcher.ExpressionError
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("expected an expression error, got %v", err)
+			}
+
+			if exprErr.Column != test.column {
+				t.Fatalf("expected error at column %d, got %d (%v)", test.column, exprErr.Column, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher
+
+import (
+	"slices"
+
+	"github.com/joshdk/krf/resources"
+)
+
+// Locator represents a Matcher which can also report the specific lines of a
+// resources.Resource that caused it to match. For example, the line of a
+// field that matched a jsonpath.
+type Locator interface {
+	Matcher
+
+	// Locate returns the lines (within the file from which the given
+	// resources.Resource was decoded) that caused it to match. Only called
+	// for resources that matched.
+	Locate(item resources.Resource) []int
+}
+
+// Locate calls Locate on the given Matcher if it is a Locator, and returns
+// nothing otherwise. The returned lines are sorted and unique.
+func Locate(matcher Matcher, item resources.Resource) []int {
+	lm, ok := matcher.(Locator)
+	if !ok {
+		return nil
+	}
+
+	lines := lm.Locate(item)
+
+	slices.Sort(lines)
+
+	return slices.Compact(lines)
+}
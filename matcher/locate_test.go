@@ -0,0 +1,96 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/joshdk/krf/matcher"
+	"github.com/joshdk/krf/resources"
+)
+
+func TestLocate(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	index := slices.IndexFunc(testResources, func(item resources.Resource) bool {
+		return item.GetKind() == "Deployment" && item.GetName() == "nginx-deployment"
+	})
+	deployment := testResources[index]
+
+	anyOf := func(matchers ...matcher.Matcher) matcher.Matcher {
+		var m matcher.AnyMatcher
+		for _, item := range matchers {
+			m.Append(item)
+		}
+
+		return &m
+	}
+
+	tests := []struct {
+		title    string
+		matcher  matcher.Matcher
+		expected []int
+	}{
+		{
+			title:    "jsonpath scalar",
+			matcher:  must(matcher.NewJsonpathMatcher(".spec.replicas")),
+			expected: []int{12},
+		},
+		{
+			title:    "jsonpath map",
+			matcher:  must(matcher.NewJsonpathMatcher(".metadata.labels")),
+			expected: []int{6},
+		},
+		{
+			title:    "jsonpath recursive value",
+			matcher:  must(matcher.NewJsonpathMatcher("..app=nginx")),
+			expected: []int{7, 15, 19},
+		},
+		{
+			title:    "jsonpath list index",
+			matcher:  must(matcher.NewJsonpathMatcher(".spec.template.spec.containers[0].ports[*].containerPort")),
+			expected: []int{28},
+		},
+		{
+			title:   "jsonpath filter",
+			matcher: must(matcher.NewJsonpathMatcher(".spec.template.spec.containers[?(@.name=='nginx')].image")),
+		},
+		{
+			title:    "contains",
+			matcher:  must(matcher.NewContainsMatcher("example-secrets")),
+			expected: []int{26},
+		},
+		{
+			title:    "fieldpath",
+			matcher:  must(matcher.NewFieldPathMatcher("spec.template.spec.containers[0].image")),
+			expected: []int{23},
+		},
+		{
+			title:   "not located",
+			matcher: must(matcher.NewKindMatcher("deploy")),
+		},
+		{
+			title: "any",
+			matcher: anyOf(
+				must(matcher.NewJsonpathMatcher(".spec.replicas")),
+				must(matcher.NewContainsMatcher("missing")),
+				must(matcher.NewContainsMatcher("proxy")),
+			),
+			expected: []int{8, 10, 12},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := matcher.Locate(test.matcher, deployment); !slices.Equal(test.expected, actual) {
+				t.Errorf("expected lines %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
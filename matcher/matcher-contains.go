@@ -34,3 +34,17 @@ func (m containsMatcher) Matches(item resources.Resource) bool {
 
 	return strings.Contains(string(body), m.substring)
 }
+
+// Locate returns each of the original source lines of the given
+// resources.Resource that contain the substring.
+func (m containsMatcher) Locate(item resources.Resource) []int {
+	var lines []int
+
+	for i, line := range resources.SourceLines(item) {
+		if strings.Contains(line, m.substring) {
+			lines = append(lines, item.GetLine()+i)
+		}
+	}
+
+	return lines
+}
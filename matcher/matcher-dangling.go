@@ -0,0 +1,79 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/joshdk/krf/references"
+	"github.com/joshdk/krf/resources"
+)
+
+// NewDanglingReferenceMatcher matches resources.Resource instances that
+// reference another resource (of a known kind, in the same namespace) which
+// is not present in the corpus of all decoded resources.
+//
+// For example, a Deployment that mounts a ConfigMap named `settings` would be
+// matched if no ConfigMap named `settings` was decoded.
+func NewDanglingReferenceMatcher() Matcher {
+	return &danglingReferenceMatcher{}
+}
+
+type danglingReferenceMatcher struct {
+	index references.Index
+}
+
+func (m *danglingReferenceMatcher) Prepare(all []resources.Resource) {
+	items := make([]unstructured.Unstructured, len(all))
+	for i, item := range all {
+		items[i] = item.Unstructured
+	}
+
+	m.index = references.NewIndex(items)
+}
+
+func (m *danglingReferenceMatcher) Matches(item resources.Resource) bool {
+	var dangling bool
+
+	m.index.Dangling(item.Unstructured, func(_, _ string) {
+		dangling = true
+	})
+
+	return dangling
+}
+
+// DanglingReferences returns a Matcher which matches the resources.Resource
+// instances matched by any dangling reference matcher within the given
+// Matcher (like one used within a filter expression), or nil if there are
+// none. Used to determine if any matching resources have dangling references,
+// regardless of how the dangling reference matcher was combined with others.
+func DanglingReferences(matcher Matcher) Matcher {
+	var found AnyMatcher
+
+	findDangling(matcher, &found)
+
+	if len(found.matchers) == 0 {
+		return nil
+	}
+
+	return &found
+}
+
+// findDangling appends every dangling reference matcher within the given
+// Matcher to the given AnyMatcher.
+func findDangling(matcher Matcher, found *AnyMatcher) {
+	if w, ok := matcher.(wrapper); ok {
+		for _, wrapped := range w.wrapped() {
+			findDangling(wrapped, found)
+		}
+
+		return
+	}
+
+	if _, ok := matcher.(*danglingReferenceMatcher); ok {
+		found.Append(matcher)
+	}
+}
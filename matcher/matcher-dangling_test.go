@@ -0,0 +1,70 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/joshdk/krf/matcher"
+	"github.com/joshdk/krf/resources"
+)
+
+func TestDanglingReferenceMatcher(t *testing.T) {
+	t.Parallel()
+
+	secret := newResource("v1", "Secret", "default", "example-secrets")
+
+	testMatcher(t, []spec{
+		{
+			title:   "dangling references",
+			matcher: matcher.NewDanglingReferenceMatcher(),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+				"Deployment/nginx-deployment",
+				"Pod/test-pod",
+			},
+		},
+		{
+			title:   "resolved reference",
+			matcher: matcher.NewDanglingReferenceMatcher(),
+			items:   append([]resources.Resource{secret}, testResources...),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+				"Pod/test-pod",
+			},
+		},
+		{
+			title:   "not dangling references",
+			matcher: matcher.NotMatcher(matcher.NewDanglingReferenceMatcher()),
+			matches: []string{
+				"ConfigMap/my-configmap",
+				"Service/my-service",
+			},
+		},
+		{
+			title:   "dangling references within not",
+			matcher: matcher.DanglingReferences(matcher.NotMatcher(matcher.NewDanglingReferenceMatcher())),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+				"Deployment/nginx-deployment",
+				"Pod/test-pod",
+			},
+		},
+	})
+}
+
+func TestDanglingReferences(t *testing.T) {
+	t.Parallel()
+
+	kind, err := matcher.NewKindMatcher("deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found := matcher.DanglingReferences(matcher.NotMatcher(kind)); found != nil {
+		t.Errorf("expected no dangling reference matchers, got %v", found)
+	}
+}
@@ -6,9 +6,11 @@
 package matcher
 
 import (
-	"github.com/google/go-cmp/cmp"
+	"slices"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
+	"github.com/joshdk/krf/diff"
 	"github.com/joshdk/krf/resources"
 )
 
@@ -18,43 +20,92 @@ import (
 // kustomize code, then rerun `kustomize build` to check if resources were
 // changed.
 func NewDiffMatcher(filename string) (Matcher, error) {
-	var originals []unstructured.Unstructured
+	return NewDiffStatusMatcher(filename, nil, diff.Options{})
+}
 
+// NewDiffStatusMatcher matches resources.Resource instances that have one of
+// the given statuses (added, changed, removed, or unchanged) when compared
+// against companion resources decoded from the given filename. If no statuses
+// are given, then resources that were added, changed, or removed are matched.
+// Resources are paired with, and then compared against, their original
+// counterparts using the given diff.Options. The given resources.Option values
+// are used when decoding the companion resources.
+//
+// Only resources that were explicitly marked as removed (see
+// resources.Resource.AsRemoved) are considered removed, as they would
+// otherwise not be present to be matched.
+func NewDiffStatusMatcher(filename string, statuses []string, options diff.Options, opts ...resources.Option) (Matcher, error) {
 	// Decode resources from the given file to be used later for matching.
+	var baseline []resources.Resource
+
 	if err := resources.Decode(filename, func(item resources.Resource) {
-		originals = append(originals, item.Unstructured)
-	}); err != nil {
+		baseline = append(baseline, item)
+	}, opts...); err != nil {
 		return nil, err
 	}
 
-	return diffMatcher{originals: originals}, nil
+	return NewDiffBaselineMatcher(baseline, statuses, options)
+}
+
+// NewDiffBaselineMatcher is the same as NewDiffStatusMatcher, but compares
+// against the given (already decoded) baseline resources.
+func NewDiffBaselineMatcher(baseline []resources.Resource, statuses []string, options diff.Options) (Matcher, error) {
+	m := diffMatcher{options: options}
+
+	for _, name := range statuses {
+		status, err := diff.ParseStatus(name)
+		if err != nil {
+			return nil, err
+		}
+
+		m.statuses = append(m.statuses, status)
+	}
+
+	if len(m.statuses) == 0 {
+		m.statuses = []diff.Status{diff.Added, diff.Changed, diff.Removed}
+	}
+
+	// Index the originals by their identity, keeping the first original for
+	// each, so that counterparts can be found without a linear scan.
+	m.originals = make(map[string]unstructured.Unstructured, len(baseline))
+
+	for _, item := range baseline {
+		key := options.Identity.OriginalKey(item.Unstructured)
+		if _, found := m.originals[key]; !found {
+			m.originals[key] = item.Unstructured
+		}
+	}
+
+	return m, nil
 }
 
 type diffMatcher struct {
-	originals []unstructured.Unstructured
+	originals map[string]unstructured.Unstructured
+	statuses  []diff.Status
+	options   diff.Options
 }
 
 func (m diffMatcher) Matches(item resources.Resource) bool {
-	for _, original := range m.originals {
-		switch {
-		// Check if the resource under investigation is the counterpart of any
-		// original resources.
-		case item.GetAPIVersion() != original.GetAPIVersion():
-			continue
-		case item.GetKind() != original.GetKind():
-			continue
-		case item.GetName() != original.GetName():
-			continue
-		case item.GetNamespace() != original.GetNamespace():
-			continue
+	return slices.Contains(m.statuses, m.status(item))
+}
+
+// status classifies the given resource relative to its original counterpart.
+func (m diffMatcher) status(item resources.Resource) diff.Status {
+	if item.IsRemoved() {
+		return diff.Removed
+	}
+
+	// Check if the resource under investigation is the counterpart of any
+	// original resources, and then diff the two.
+	if original, found := m.originals[m.options.Identity.Key(item.Unstructured)]; found {
+		if len(m.options.Changes(original.Object, item.Object)) == 0 {
+			return diff.Unchanged
 		}
 
-		// Diff the resource under investigation against its original
-		// counterpart.
-		return !cmp.Equal(item.Object, original.Object)
+		return diff.Changed
 	}
 
 	// The resource under investigation did not have an original counterpart,
-	// and has therefore differed.
-	return true
+	// and has therefore been added.
+	return diff.Added
 }
@@ -8,9 +8,19 @@ package matcher_test
 import (
 	"testing"
 
+	"github.com/joshdk/krf/diff"
 	"github.com/joshdk/krf/matcher"
+	"github.com/joshdk/krf/resources"
 )
 
+// removedResources returns the shared set of decoded resources, along with a
+// resource that was marked as removed.
+func removedResources() []resources.Resource {
+	return append([]resources.Resource{
+		newResource("v1", "Secret", "default", "example-secrets").AsRemoved(),
+	}, testResources...)
+}
+
 func TestDiffMatcher(t *testing.T) {
 	t.Parallel()
 
@@ -24,5 +34,129 @@ func TestDiffMatcher(t *testing.T) {
 				"Pod/test-pod",
 			},
 		},
+		{
+			title:   "diff removed resources",
+			matcher: must(matcher.NewDiffMatcher("testdata/resources.txt")),
+			items:   removedResources(),
+			matches: []string{
+				"ConfigMap/my-configmap",
+				"Deployment/nginx-deployment",
+				"Pod/test-pod",
+				"Secret/example-secrets",
+			},
+		},
+	})
+}
+
+func TestDiffStatusMatcher(t *testing.T) {
+	t.Parallel()
+
+	testMatcher(t, []spec{
+		{
+			title:   "added",
+			matcher: must(matcher.NewDiffStatusMatcher("testdata/resources.txt", []string{"added"}, diff.Options{})),
+			items:   removedResources(),
+			matches: []string{
+				"ConfigMap/my-configmap",
+			},
+		},
+		{
+			title:   "changed",
+			matcher: must(matcher.NewDiffStatusMatcher("testdata/resources.txt", []string{"changed"}, diff.Options{})),
+			items:   removedResources(),
+			matches: []string{
+				"Deployment/nginx-deployment",
+				"Pod/test-pod",
+			},
+		},
+		{
+			title:   "removed",
+			matcher: must(matcher.NewDiffStatusMatcher("testdata/resources.txt", []string{"removed"}, diff.Options{})),
+			items:   removedResources(),
+			matches: []string{
+				"Secret/example-secrets",
+			},
+		},
+		{
+			title:   "unchanged",
+			matcher: must(matcher.NewDiffStatusMatcher("testdata/resources.txt", []string{"unchanged"}, diff.Options{})),
+			items:   removedResources(),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+				"Service/my-service",
+			},
+		},
+		{
+			title:   "multiple statuses",
+			matcher: must(matcher.NewDiffStatusMatcher("testdata/resources.txt", []string{"added", "removed"}, diff.Options{})),
+			items:   removedResources(),
+			matches: []string{
+				"ConfigMap/my-configmap",
+				"Secret/example-secrets",
+			},
+		},
+	})
+}
+
+func TestDiffIdentity(t *testing.T) {
+	t.Parallel()
+
+	testMatcher(t, []spec{
+		{
+			title:   "ignore namespace",
+			matcher: must(matcher.NewDiffStatusMatcher("testdata/resources.txt", []string{"added"}, diff.Options{Identity: diff.Identity{IgnoreNamespace: true}})),
+			matches: []string{},
+		},
+		{
+			title:   "ignore namespace changed",
+			matcher: must(matcher.NewDiffStatusMatcher("testdata/resources.txt", []string{"changed"}, diff.Options{Identity: diff.Identity{IgnoreNamespace: true}})),
+			matches: []string{
+				"ConfigMap/my-configmap",
+				"Deployment/nginx-deployment",
+				"Pod/test-pod",
+			},
+		},
+		{
+			title:   "renamed",
+			matcher: must(matcher.NewDiffStatusMatcher("testdata/resources.txt", []string{"added"}, diff.Options{Identity: diff.Identity{Renames: map[string]string{"my-service": "other-service"}}})),
+			matches: []string{
+				"ConfigMap/my-configmap",
+				"Service/my-service",
+			},
+		},
+	})
+}
+
+func TestDiffIgnore(t *testing.T) {
+	t.Parallel()
+
+	ignore := func(paths []string, rules []string) diff.Options {
+		ignore, err := diff.ParseIgnore(paths, rules)
+		if err != nil {
+			panic(err)
+		}
+
+		return diff.Options{Ignore: ignore}
+	}
+
+	testMatcher(t, []spec{
+		{
+			title:   "ignore paths",
+			matcher: must(matcher.NewDiffStatusMatcher("testdata/resources.txt", []string{"unchanged"}, ignore([]string{".spec.containers[*].command[0]"}, nil))),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+				"Pod/test-pod",
+				"Service/my-service",
+			},
+		},
+		{
+			title:   "ignore cel rules",
+			matcher: must(matcher.NewDiffStatusMatcher("testdata/resources.txt", []string{"unchanged"}, ignore(nil, []string{"before.split('/')[1] == after.split('/')[1]"}))),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+				"Pod/test-pod",
+				"Service/my-service",
+			},
+		},
 	})
 }
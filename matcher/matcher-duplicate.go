@@ -0,0 +1,73 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher
+
+import (
+	"github.com/joshdk/krf/resources"
+)
+
+// NewDuplicateMatcher matches resources.Resource instances that share the
+// same API group, kind, namespace, and name with at least one other resource
+// in the corpus of all decoded resources. Such resources would overwrite each
+// other when applied to a cluster. Kinds that were served by the legacy
+// "extensions" API group are treated as belonging to the group that now
+// serves them.
+func NewDuplicateMatcher() Matcher {
+	return &duplicateMatcher{}
+}
+
+type duplicateMatcher struct {
+	counts map[duplicateKey]int
+}
+
+// duplicateKey identifies a resource as it would be identified by a cluster.
+// The API version is intentionally omitted, as the same resource can be
+// served by multiple versions.
+type duplicateKey struct {
+	group     string
+	kind      string
+	namespace string
+	name      string
+}
+
+// legacyGroups maps each kind once served by the "extensions" API group to the
+// group that now serves the same resources.
+var legacyGroups = map[string]string{ //nolint:gochecknoglobals
+	"DaemonSet":         "apps",
+	"Deployment":        "apps",
+	"Ingress":           "networking.k8s.io",
+	"NetworkPolicy":     "networking.k8s.io",
+	"PodSecurityPolicy": "policy",
+	"ReplicaSet":        "apps",
+}
+
+func newDuplicateKey(item resources.Resource) duplicateKey {
+	gvk := item.GroupVersionKind()
+
+	group := gvk.Group
+	if alias, found := legacyGroups[gvk.Kind]; found && group == "extensions" {
+		group = alias
+	}
+
+	return duplicateKey{
+		group:     group,
+		kind:      gvk.Kind,
+		namespace: item.GetNamespace(),
+		name:      item.GetName(),
+	}
+}
+
+func (m *duplicateMatcher) Prepare(all []resources.Resource) {
+	m.counts = make(map[duplicateKey]int)
+
+	for _, item := range all {
+		m.counts[newDuplicateKey(item)]++
+	}
+}
+
+func (m *duplicateMatcher) Matches(item resources.Resource) bool {
+	return m.counts[newDuplicateKey(item)] > 1
+}
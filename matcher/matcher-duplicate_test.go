@@ -0,0 +1,67 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/joshdk/krf/matcher"
+	"github.com/joshdk/krf/resources"
+)
+
+func TestDuplicateMatcher(t *testing.T) {
+	t.Parallel()
+
+	testMatcher(t, []spec{
+		{
+			title:   "no duplicates",
+			matcher: matcher.NewDuplicateMatcher(),
+			matches: []string{},
+		},
+		{
+			title:   "duplicates",
+			matcher: matcher.NewDuplicateMatcher(),
+			items: append([]resources.Resource{
+				newResource("v1", "Service", "custom-app", "my-service"),
+				newResource("v1", "Service", "default", "my-service"),
+			}, testResources...),
+			matches: []string{
+				"Service/my-service",
+			},
+		},
+		{
+			title:   "same group with different versions",
+			matcher: matcher.NewDuplicateMatcher(),
+			items: []resources.Resource{
+				newResource("apps/v1beta1", "Deployment", "default", "web"),
+				newResource("apps/v1", "Deployment", "default", "web"),
+			},
+			matches: []string{
+				"Deployment/web",
+			},
+		},
+		{
+			title:   "legacy extensions group",
+			matcher: matcher.NewDuplicateMatcher(),
+			items: []resources.Resource{
+				newResource("extensions/v1beta1", "Deployment", "default", "web"),
+				newResource("apps/v1", "Deployment", "default", "web"),
+			},
+			matches: []string{
+				"Deployment/web",
+			},
+		},
+		{
+			title:   "different groups",
+			matcher: matcher.NewDuplicateMatcher(),
+			items: []resources.Resource{
+				newResource("example.com/v1", "Deployment", "default", "web"),
+				newResource("apps/v1", "Deployment", "default", "web"),
+			},
+			matches: []string{},
+		},
+	})
+}
@@ -7,8 +7,10 @@ package matcher
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/gobwas/glob"
+	"sigs.k8s.io/kustomize/kyaml/utils"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 
 	"github.com/joshdk/krf/resources"
@@ -71,3 +73,39 @@ func (m fieldPathMatcher) Matches(item resources.Resource) bool {
 
 	return false
 }
+
+// Locate returns the original source line of the field at the fieldpath.
+func (m fieldPathMatcher) Locate(item resources.Resource) []int {
+	node, offset := resources.SourceNode(item)
+	if node == nil {
+		return nil
+	}
+
+	// Split the fieldpath in the same way as yaml.RNode.GetFieldValue, where
+	// list indexes like "ports[0]" are split into a separate "0" field.
+	var fields []string
+
+	for _, field := range utils.SmarterPathSplitter(m.fieldPath, ".") {
+		if groups := sliceIndexPattern.FindStringSubmatch(field); groups != nil {
+			if groups[1] != "" {
+				fields = append(fields, groups[1])
+			}
+
+			fields = append(fields, groups[2])
+
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	field, err := node.Pipe(yaml.Lookup(fields...))
+	if err != nil || field == nil {
+		return nil
+	}
+
+	return []int{field.YNode().Line + offset}
+}
+
+// sliceIndexPattern matches a fieldpath field with a trailing list index.
+var sliceIndexPattern = regexp.MustCompile(`^(.*)\[(\d+)\]$`)
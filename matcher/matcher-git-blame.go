@@ -0,0 +1,107 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+
+	"github.com/joshdk/krf/resources"
+)
+
+// NewGitAuthorMatcher matches resources.Resource instances where any of the
+// lines occupied by the resource were last modified (according to git blame)
+// by an author whose name or email address matches the given glob.
+func NewGitAuthorMatcher(author string) (Matcher, error) {
+	authorGlob, err := glob.Compile(strings.ToLower(author))
+	if err != nil {
+		return nil, err
+	}
+
+	return gitAuthorMatcher{authorGlob: authorGlob}, nil
+}
+
+type gitAuthorMatcher struct {
+	authorGlob glob.Glob
+}
+
+func (m gitAuthorMatcher) Matches(item resources.Resource) bool {
+	lines, err := resources.Blame(item)
+	if err != nil {
+		return false
+	}
+
+	for _, line := range lines {
+		if m.authorGlob.Match(strings.ToLower(line.Author)) || m.authorGlob.Match(strings.ToLower(line.Email)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewGitSinceMatcher matches resources.Resource instances where any of the
+// lines occupied by the resource were last modified (according to git blame)
+// after the given time. The time can either be a date (like "2024-01-31"), a
+// timestamp (like "2024-01-31T12:00:00Z"), or a duration before the current
+// time (like "36h", "2d", or "4w").
+func NewGitSinceMatcher(since string) (Matcher, error) {
+	timestamp, err := parseSince(since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return gitSinceMatcher{since: timestamp}, nil
+}
+
+type gitSinceMatcher struct {
+	since time.Time
+}
+
+func (m gitSinceMatcher) Matches(item resources.Resource) bool {
+	lines, err := resources.Blame(item)
+	if err != nil {
+		return false
+	}
+
+	for _, line := range lines {
+		if line.Date.After(m.since) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseSince parses the given date, timestamp, or duration before now.
+func parseSince(since string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if timestamp, err := time.Parse(layout, since); err == nil {
+			return timestamp, nil
+		}
+	}
+
+	// Support day and week durations, in addition to those supported by
+	// time.ParseDuration.
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} { //nolint:mnd
+		if count, found := strings.CutSuffix(since, suffix); found {
+			if number, err := strconv.Atoi(count); err == nil {
+				return now.Add(-time.Duration(number) * unit), nil
+			}
+		}
+	}
+
+	duration, err := time.ParseDuration(since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time or duration: %q", since)
+	}
+
+	return now.Add(-duration), nil
+}
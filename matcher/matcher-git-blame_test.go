@@ -0,0 +1,119 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+
+	"github.com/joshdk/krf/matcher"
+	"github.com/joshdk/krf/resources"
+)
+
+func TestGitBlameMatchers(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	directory := t.TempDir()
+
+	repository, err := git.PlainInit(directory, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Commit a file containing one resource written by each author.
+	commit := func(name, author string, when time.Time) {
+		contents := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n"
+
+		if err := os.WriteFile(filepath.Join(directory, name+".yaml"), []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := worktree.Add(name + ".yaml"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := worktree.Commit("test", &git.CommitOptions{
+			Author: &object.Signature{Name: author, Email: author + "@example.com", When: when},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commit("old", "Alice", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	commit("new", "Bob", time.Now().Add(-time.Hour))
+
+	var items []resources.Resource
+
+	if err := resources.Decode(directory, func(item resources.Resource) {
+		items = append(items, item)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	testMatcher(t, []spec{
+		{
+			title:   "author name",
+			matcher: must(matcher.NewGitAuthorMatcher("alice")),
+			items:   items,
+			matches: []string{
+				"ConfigMap/old",
+			},
+		},
+		{
+			title:   "author email glob",
+			matcher: must(matcher.NewGitAuthorMatcher("*@example.com")),
+			items:   items,
+			matches: []string{
+				"ConfigMap/new",
+				"ConfigMap/old",
+			},
+		},
+		{
+			title:   "unknown author",
+			matcher: must(matcher.NewGitAuthorMatcher("carol")),
+			items:   items,
+		},
+		{
+			title:   "since duration",
+			matcher: must(matcher.NewGitSinceMatcher("2d")),
+			items:   items,
+			matches: []string{
+				"ConfigMap/new",
+			},
+		},
+		{
+			title:   "since date",
+			matcher: must(matcher.NewGitSinceMatcher("2019-12-31")),
+			items:   items,
+			matches: []string{
+				"ConfigMap/new",
+				"ConfigMap/old",
+			},
+		},
+		{
+			title:   "since timestamp",
+			matcher: must(matcher.NewGitSinceMatcher("2021-01-01T00:00:00Z")),
+			items:   items,
+			matches: []string{
+				"ConfigMap/new",
+			},
+		},
+	})
+
+	if _, err := matcher.NewGitSinceMatcher("yesterday"); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+}
@@ -6,61 +6,416 @@
 package matcher
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/go-git/go-billy/v6/osfs"
 	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v6/plumbing/format/index"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/storer"
+	"github.com/go-git/go-git/v6/utils/merkletrie"
 
 	"github.com/joshdk/krf/resources"
 )
 
-func gitStatus() (string, git.Status, error) {
-	repository, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true})
+// gitWorktree is a snapshot of the git repository containing the current
+// directory.
+type gitWorktree struct {
+	// root is the root directory of the worktree.
+	root string
+
+	// status is the status of every file in the worktree.
+	status git.Status
+
+	// ignored matches files that are ignored by a .gitignore file.
+	ignored gitignore.Matcher
+
+	// tree returns the tree at the given revision.
+	tree func(revision string) (*object.Tree, error)
+}
+
+func gitStatus() (gitWorktree, error) {
+	return openGitWorktree(".")
+}
+
+// openGitWorktree returns a snapshot of the git repository containing the
+// given directory.
+func openGitWorktree(directory string) (gitWorktree, error) {
+	repository, err := git.PlainOpenWithOptions(directory, &git.PlainOpenOptions{DetectDotGit: true})
 	if err != nil {
-		return "", nil, err
+		return gitWorktree{}, err
 	}
 
 	worktree, err := repository.Worktree()
 	if err != nil {
-		return "", nil, err
+		return gitWorktree{}, err
 	}
 
 	filesystem, ok := worktree.Filesystem.(*osfs.BoundOS)
 	if !ok {
-		return "", nil, errors.New("could not determine git repo directory")
+		return gitWorktree{}, errors.New("could not determine git repo directory")
 	}
 
 	status, err := worktree.StatusWithOptions(git.StatusOptions{Strategy: git.Preload})
 	if err != nil {
-		return "", nil, err
+		return gitWorktree{}, err
+	}
+
+	if err := detectRenames(repository, status); err != nil {
+		return gitWorktree{}, err
+	}
+
+	patterns, err := gitignore.ReadPatterns(worktree.Filesystem, nil)
+	if err != nil {
+		return gitWorktree{}, err
+	}
+
+	return gitWorktree{
+		root:    filesystem.Root(),
+		status:  status,
+		ignored: gitignore.NewMatcher(append(patterns, worktree.Excludes...)),
+		tree: func(revision string) (*object.Tree, error) {
+			return gitTree(repository, revision)
+		},
+	}, nil
+}
+
+// detectRenames updates the given status with the files that were renamed or
+// copied in the index, as go-git otherwise reports these files as added, and
+// with the files that were deleted from the index while remaining in the
+// worktree. As with git status, renames are detected by comparing the tree at
+// HEAD against the index, and copies are files added with the same contents
+// as a file that was already committed.
+func detectRenames(repository *git.Repository, status git.Status) error {
+	head, err := repository.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		// Nothing can be renamed or copied before the first commit.
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	commit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+
+	headTree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	idx, err := repository.Storer.Index()
+	if err != nil {
+		return err
+	}
+
+	indexTree, err := writeIndexTree(indexStorer{
+		EncodedObjectStorer: repository.Storer,
+		trees:               make(map[plumbing.Hash]plumbing.EncodedObject),
+	}, idx)
+	if err != nil {
+		return err
+	}
+
+	changes, err := object.DiffTreeWithOptions(context.Background(), headTree, indexTree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return err
+	}
+
+	// Empty files are never considered to be copies.
+	empty := repository.Storer.NewEncodedObject()
+	empty.SetType(plumbing.BlobObject)
+
+	committed, err := treeFiles(headTree, empty.Hash())
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case action == merkletrie.Modify && change.From.Name != change.To.Name:
+			if fileStatus, ok := status[change.To.Name]; ok {
+				fileStatus.Staging = git.Renamed
+				fileStatus.Extra = change.From.Name
+			}
+
+		case action == merkletrie.Insert:
+			if fileStatus, ok := status[change.To.Name]; ok {
+				if source, found := committed[change.To.TreeEntry.Hash]; found {
+					fileStatus.Staging = git.Copied
+					fileStatus.Extra = source
+				}
+			}
+
+		case action == merkletrie.Delete:
+			// Files removed from the index but not from the worktree (like
+			// with "git rm --cached") are reported as only untracked.
+			if fileStatus, ok := status[change.From.Name]; ok && fileStatus.Staging == git.Untracked {
+				fileStatus.Staging = git.Deleted
+			}
+		}
+	}
+
+	return nil
+}
+
+// treeFiles returns the name of a regular file in the given tree for each
+// distinct blob, except for the given (empty) blob.
+func treeFiles(tree *object.Tree, empty plumbing.Hash) (map[plumbing.Hash]string, error) {
+	files := make(map[plumbing.Hash]string)
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if _, found := files[entry.Hash]; !found && entry.Mode.IsFile() && entry.Hash != empty {
+			files[entry.Hash] = name
+		}
+	}
+}
+
+// indexStorer holds the trees written for the index in memory, so that the
+// repository itself is never modified, while reading every other object
+// from the repository.
+type indexStorer struct {
+	storer.EncodedObjectStorer
+
+	trees map[plumbing.Hash]plumbing.EncodedObject
+}
+
+func (s indexStorer) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	s.trees[obj.Hash()] = obj
+
+	return obj.Hash(), nil
+}
+
+func (s indexStorer) EncodedObject(kind plumbing.ObjectType, hash plumbing.Hash) (plumbing.EncodedObject, error) {
+	if obj, found := s.trees[hash]; found && (kind == plumbing.AnyObject || kind == obj.Type()) {
+		return obj, nil
+	}
+
+	return s.EncodedObjectStorer.EncodedObject(kind, hash)
+}
+
+// writeIndexTree writes the entries of the given index as a tree (and
+// subtrees) to the given storer, like git write-tree.
+func writeIndexTree(s storer.EncodedObjectStorer, idx *index.Index) (*object.Tree, error) {
+	trees := map[string]*object.Tree{".": {}}
+
+	// addDir adds the given directory, along with each of its parents.
+	var addDir func(directory string)
+	addDir = func(directory string) {
+		if _, found := trees[directory]; found {
+			return
+		}
+
+		parent := path.Dir(directory)
+		addDir(parent)
+
+		trees[directory] = &object.Tree{}
+		trees[parent].Entries = append(trees[parent].Entries, object.TreeEntry{
+			Name: path.Base(directory),
+			Mode: filemode.Dir,
+		})
+	}
+
+	for _, entry := range idx.Entries {
+		// Skip the conflicting versions of any unmerged files.
+		if entry.Stage >= index.AncestorMode {
+			continue
+		}
+
+		directory := path.Dir(entry.Name)
+		addDir(directory)
+
+		trees[directory].Entries = append(trees[directory].Entries, object.TreeEntry{
+			Name: path.Base(entry.Name),
+			Mode: entry.Mode,
+			Hash: entry.Hash,
+		})
+	}
+
+	hash, err := writeTree(s, trees, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	return object.GetTree(s, hash)
+}
+
+// writeTree writes the tree for the given directory, after first writing each
+// of its subtrees.
+func writeTree(s storer.EncodedObjectStorer, trees map[string]*object.Tree, directory string) (plumbing.Hash, error) {
+	tree := trees[directory]
+
+	for i, entry := range tree.Entries {
+		if entry.Mode != filemode.Dir {
+			continue
+		}
+
+		hash, err := writeTree(s, trees, path.Join(directory, entry.Name))
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		tree.Entries[i].Hash = hash
+	}
+
+	// Git sorts directories as if their names had a trailing slash.
+	sortName := func(entry object.TreeEntry) string {
+		if entry.Mode == filemode.Dir {
+			return entry.Name + "/"
+		}
+
+		return entry.Name
+	}
+
+	slices.SortFunc(tree.Entries, func(a, b object.TreeEntry) int {
+		return strings.Compare(sortName(a), sortName(b))
+	})
+
+	obj := s.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return s.SetEncodedObject(obj)
+}
+
+// gitTree returns the tree at the given revision. As with git diff, a
+// revision like "main..." refers to the merge base of "main" and "HEAD".
+func gitTree(repository *git.Repository, revision string) (*object.Tree, error) {
+	var commit *object.Commit
+
+	if left, right, found := strings.Cut(revision, "..."); found {
+		if right == "" {
+			right = "HEAD"
+		}
+
+		leftCommit, err := gitCommit(repository, left)
+		if err != nil {
+			return nil, err
+		}
+
+		rightCommit, err := gitCommit(repository, right)
+		if err != nil {
+			return nil, err
+		}
+
+		bases, err := leftCommit.MergeBase(rightCommit)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(bases) == 0 {
+			return nil, fmt.Errorf("no merge base found for %q", revision)
+		}
+
+		commit = bases[0]
+	} else {
+		var err error
+		if commit, err = gitCommit(repository, revision); err != nil {
+			return nil, err
+		}
 	}
 
-	return filesystem.Root(), status, nil
+	return commit.Tree()
+}
+
+// gitCommit returns the commit at the given revision.
+func gitCommit(repository *git.Repository, revision string) (*object.Commit, error) {
+	hash, err := repository.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("resolving git revision %q: %w", revision, err)
+	}
+
+	return repository.CommitObject(*hash)
 }
 
 // NewGitMatcher matches resources.Resource instances based on the current git
-// status of the underlying resource file.
+// status of the underlying resource file. The pattern is one of:
+//   - added, modified, unmodified, or untracked.
+//   - staged or unstaged, for files with changes in the index or worktree.
+//   - renamed or copied, for files renamed or copied in the index.
+//   - deleted, for files removed from the index that still exist in the
+//     worktree (like with "git rm --cached"), as other deleted files have no
+//     resources to be matched.
+//   - ignored, for untracked files ignored by a .gitignore file.
+//   - changed-since=<rev>, for files that differ from those in the tree at the
+//     given revision (like "HEAD~3", or "main..." for the merge base).
 func NewGitMatcher(pattern string) (Matcher, error) {
 	return newGitMatcher(os.Getwd, gitStatus, pattern)
 }
 
-func newGitMatcher(getwd func() (string, error), statusFn func() (string, git.Status, error), pattern string) (Matcher, error) {
+// Pseudo status codes used for patterns that do not correspond to a single
+// git.StatusCode. The ignored code matches "git status --ignored".
+const (
+	gitChangedSince git.StatusCode = '>'
+	gitIgnored      git.StatusCode = '!'
+	gitStaged       git.StatusCode = '+'
+	gitUnstaged     git.StatusCode = '-'
+)
+
+func newGitMatcher(getwd func() (string, error), statusFn func() (gitWorktree, error), pattern string) (Matcher, error) {
 	var statusCode git.StatusCode
 
-	switch pattern {
-	case "added", "a", "A":
-		statusCode = git.Added
-	case "modified", "m", "M":
-		statusCode = git.Modified
-	case "unmodified", "u", "U", " ":
-		statusCode = git.Unmodified
-	case "untracked", "?":
-		statusCode = git.Untracked
+	revision, isChangedSince := strings.CutPrefix(pattern, "changed-since=")
+
+	switch {
+	case isChangedSince && revision != "":
+		statusCode = gitChangedSince
+	case isChangedSince:
+		return nil, errors.New("changed-since requires a git revision")
 	default:
-		return nil, fmt.Errorf("unsupported pattern: %q", pattern)
+		switch pattern {
+		case "added", "a", "A":
+			statusCode = git.Added
+		case "modified", "m", "M":
+			statusCode = git.Modified
+		case "unmodified", "u", "U", " ":
+			statusCode = git.Unmodified
+		case "untracked", "?":
+			statusCode = git.Untracked
+		case "deleted", "d", "D":
+			statusCode = git.Deleted
+		case "renamed", "r", "R":
+			statusCode = git.Renamed
+		case "copied", "c", "C":
+			statusCode = git.Copied
+		case "ignored", "!":
+			statusCode = gitIgnored
+		case "staged":
+			statusCode = gitStaged
+		case "unstaged":
+			statusCode = gitUnstaged
+		default:
+			return nil, fmt.Errorf("unsupported pattern: %q", pattern)
+		}
 	}
 
 	currentDir, err := getwd()
@@ -68,27 +423,51 @@ func newGitMatcher(getwd func() (string, error), statusFn func() (string, git.St
 		return nil, err
 	}
 
-	gitDir, status, err := statusFn()
+	worktree, err := statusFn()
 	if err != nil {
 		return nil, err
 	}
 
-	relRoot, err := filepath.Rel(gitDir, currentDir)
+	relRoot, err := filepath.Rel(worktree.root, currentDir)
 	if err != nil {
 		return nil, err
 	}
 
-	return gitMatcher{
+	m := gitMatcher{
 		relRoot:    relRoot,
-		status:     status,
+		status:     worktree.status,
+		ignored:    worktree.ignored,
 		statusCode: statusCode,
-	}, nil
+	}
+
+	if statusCode == gitChangedSince {
+		if m.tree, err = worktree.tree(revision); err != nil {
+			return nil, err
+		}
+
+		m.changes = &gitChanges{files: make(map[string]bool)}
+	}
+
+	return m, nil
 }
 
 type gitMatcher struct {
 	status     git.Status
+	ignored    gitignore.Matcher
 	statusCode git.StatusCode
 	relRoot    string
+
+	// tree is the tree at the changed-since revision, and changes caches
+	// whether each file differs from its counterpart in that tree.
+	tree    *object.Tree
+	changes *gitChanges
+}
+
+// gitChanges caches whether each file differs from its counterpart in the
+// changed-since tree, as resources commonly share the same file.
+type gitChanges struct {
+	sync.Mutex
+	files map[string]bool
 }
 
 func (m gitMatcher) Matches(item resources.Resource) bool {
@@ -97,23 +476,70 @@ func (m gitMatcher) Matches(item resources.Resource) bool {
 		return false
 	}
 
-	fullFile := filepath.Join(m.relRoot, filename)
+	fullFile := filepath.ToSlash(filepath.Join(m.relRoot, filename))
+
+	if m.statusCode == gitChangedSince {
+		return m.changed(filename, fullFile)
+	}
 
 	fileStatus, ok := m.status[fullFile]
 	if !ok {
+		// Files without a status are either ignored, or untracked.
+		if m.ignored != nil && m.ignored.Match(strings.Split(fullFile, "/"), false) {
+			return m.statusCode == gitIgnored
+		}
+
 		fileStatus = &git.FileStatus{Worktree: git.Untracked, Staging: git.Untracked}
 	}
 
 	switch m.statusCode { //nolint:exhaustive
 	case git.Added:
-		return fileStatus.Staging == git.Modified
+		return fileStatus.Staging == git.Added
 	case git.Modified:
 		return fileStatus.Staging == git.Modified || fileStatus.Worktree == git.Modified
 	case git.Unmodified:
 		return fileStatus.Staging == git.Unmodified && fileStatus.Worktree == git.Unmodified
 	case git.Untracked:
 		return fileStatus.Staging == git.Untracked && fileStatus.Worktree == git.Untracked
+	case git.Deleted, git.Renamed, git.Copied:
+		return fileStatus.Staging == m.statusCode
+	case gitStaged:
+		return fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked
+	case gitUnstaged:
+		return fileStatus.Worktree != git.Unmodified && fileStatus.Worktree != git.Untracked
 	default:
 		return false
 	}
 }
+
+// changed reports if the given file differs from the file (relative to the
+// root of the repository) in the changed-since tree. Files that did not exist
+// in that tree are considered changed.
+func (m gitMatcher) changed(filename, fullFile string) bool {
+	m.changes.Lock()
+	defer m.changes.Unlock()
+
+	if changed, ok := m.changes.files[filename]; ok {
+		return changed
+	}
+
+	changed := true
+
+	if original, err := m.tree.File(fullFile); err == nil {
+		current, err := os.ReadFile(filename)
+		if err != nil {
+			return false
+		}
+
+		contents, err := original.Contents()
+		if err != nil {
+			return false
+		}
+
+		changed = contents != string(current)
+	}
+
+	m.changes.files[filename] = changed
+
+	return changed
+}
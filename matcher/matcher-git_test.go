@@ -6,9 +6,13 @@
 package matcher_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
 
 	"github.com/joshdk/krf/matcher"
 )
@@ -29,8 +33,8 @@ func TestGitMatcher(t *testing.T) { //nolint:funlen
 
 	gitStatusSubdir := git.Status{
 		"subdir/testdata/service.yaml": &git.FileStatus{
-			Staging:  git.Modified,
-			Worktree: git.Unmodified,
+			Staging:  git.Added,
+			Worktree: git.Modified,
 		},
 		"subdir/testdata/subdir/deployment.yaml": &git.FileStatus{
 			Staging:  git.Unmodified,
@@ -83,3 +87,313 @@ func TestGitMatcher(t *testing.T) { //nolint:funlen
 		},
 	})
 }
+
+func TestGitMatcherStatuses(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	gitStatus := git.Status{
+		"testdata/service.yaml": &git.FileStatus{
+			Staging:  git.Modified,
+			Worktree: git.Modified,
+		},
+		"testdata/subdir/deployment.yaml": &git.FileStatus{
+			Staging:  git.Unmodified,
+			Worktree: git.Modified,
+		},
+		"testdata/subdir/clusterrolebinding.yaml": &git.FileStatus{
+			Staging:  git.Renamed,
+			Worktree: git.Unmodified,
+			Extra:    "testdata/subdir/crb.yaml",
+		},
+		"testdata/subdir/subsubdir/configmap.yaml": &git.FileStatus{
+			Staging:  git.Deleted,
+			Worktree: git.Untracked,
+		},
+	}
+
+	ignorePatterns := []string{"*.patch.yaml"}
+
+	newMatcher := func(pattern string) matcher.Matcher {
+		return must(matcher.NewTestGitWorktreeMatcher("/root/repo", "/root/repo", gitStatus, ignorePatterns, nil, pattern))
+	}
+
+	testMatcher(t, []spec{
+		{
+			title:   "staged",
+			matcher: newMatcher("staged"),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+				"ConfigMap/my-configmap",
+				"Service/my-service",
+			},
+		},
+		{
+			title:   "unstaged",
+			matcher: newMatcher("unstaged"),
+			matches: []string{
+				"Deployment/nginx-deployment",
+				"Service/my-service",
+			},
+		},
+		{
+			title:   "renamed",
+			matcher: newMatcher("renamed"),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+			},
+		},
+		{
+			title:   "deleted",
+			matcher: newMatcher("D"),
+			matches: []string{
+				"ConfigMap/my-configmap",
+			},
+		},
+		{
+			title:   "ignored",
+			matcher: newMatcher("ignored"),
+			matches: []string{
+				"Pod/test-pod",
+			},
+		},
+		{
+			title:   "untracked excludes ignored",
+			matcher: newMatcher("untracked"),
+		},
+	})
+}
+
+func TestGitMatcherChangedSince(t *testing.T) {
+	t.Parallel()
+
+	service, err := os.ReadFile("testdata/service.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Commit an identical copy of the service, and a modified copy of the
+	// deployment. All other files did not exist at this commit.
+	tree := gitTree(t, map[string]string{
+		"testdata/service.yaml":           string(service),
+		"testdata/subdir/deployment.yaml": "kind: Deployment\n",
+	})
+
+	testMatcher(t, []spec{
+		{
+			title:   "changed since",
+			matcher: must(matcher.NewTestGitWorktreeMatcher("/root/repo", "/root/repo", nil, nil, tree, "changed-since=main")),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+				"ConfigMap/my-configmap",
+				"Deployment/nginx-deployment",
+				"Pod/test-pod",
+			},
+		},
+	})
+
+	if _, err := matcher.NewTestGitWorktreeMatcher("/root/repo", "/root/repo", nil, nil, tree, "changed-since="); err == nil {
+		t.Error("expected an error for a missing revision")
+	}
+}
+
+func TestGitMatcherRepository(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	directory := t.TempDir()
+
+	repository, err := git.PlainInit(directory, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// copyFile copies the given test data file into the repository.
+	copyFile := func(source, destination string) {
+		contents, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		filename := filepath.Join(directory, destination)
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filename, contents, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// appendFile appends a comment to the given file in the repository.
+	appendFile := func(filename string) {
+		file, err := os.OpenFile(filepath.Join(directory, filename), os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := file.WriteString("# modified\n"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := file.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	add := func(filename string) {
+		if _, err := worktree.Add(filename); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	copyFile("testdata/service.yaml", "testdata/service.yaml")
+	copyFile("testdata/pod.patch.yaml", "testdata/pod.yaml")
+	copyFile("testdata/subdir/clusterrolebinding.yaml", "testdata/crb.yaml")
+	copyFile("testdata/subdir/subsubdir/configmap.yaml", "testdata/subdir/subsubdir/configmap.yaml")
+
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := worktree.Commit("test", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", When: time.Now()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stage a new deployment.
+	copyFile("testdata/subdir/deployment.yaml", "testdata/subdir/deployment.yaml")
+	add("testdata/subdir/deployment.yaml")
+
+	// Stage a renamed (and slightly modified) cluster role binding.
+	if _, err := worktree.Move("testdata/crb.yaml", "testdata/subdir/clusterrolebinding.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	appendFile("testdata/subdir/clusterrolebinding.yaml")
+	add("testdata/subdir/clusterrolebinding.yaml")
+
+	// Stage a copy of the pod.
+	copyFile("testdata/pod.patch.yaml", "testdata/pod.patch.yaml")
+	add("testdata/pod.patch.yaml")
+
+	// Remove the config map from the index, but not from the worktree.
+	if _, err := worktree.Remove("testdata/subdir/subsubdir/configmap.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	copyFile("testdata/subdir/subsubdir/configmap.yaml", "testdata/subdir/subsubdir/configmap.yaml")
+
+	// Modify the service, without staging it.
+	appendFile("testdata/service.yaml")
+
+	newMatcher := func(pattern string) matcher.Matcher {
+		return must(matcher.NewTestGitRepositoryMatcher(directory, pattern))
+	}
+
+	testMatcher(t, []spec{
+		{
+			title:   "added",
+			matcher: newMatcher("added"),
+			matches: []string{
+				"Deployment/nginx-deployment",
+			},
+		},
+		{
+			title:   "renamed",
+			matcher: newMatcher("renamed"),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+			},
+		},
+		{
+			title:   "copied",
+			matcher: newMatcher("copied"),
+			matches: []string{
+				"Pod/test-pod",
+			},
+		},
+		{
+			title:   "deleted",
+			matcher: newMatcher("deleted"),
+			matches: []string{
+				"ConfigMap/my-configmap",
+			},
+		},
+		{
+			title:   "modified",
+			matcher: newMatcher("modified"),
+			matches: []string{
+				"Service/my-service",
+			},
+		},
+		{
+			title:   "staged",
+			matcher: newMatcher("staged"),
+			matches: []string{
+				"ClusterRoleBinding/read-secrets-global",
+				"ConfigMap/my-configmap",
+				"Deployment/nginx-deployment",
+				"Pod/test-pod",
+			},
+		},
+	})
+}
+
+// gitTree commits the given files to a new repository, and returns the
+// resulting tree.
+func gitTree(t *testing.T, files map[string]string) *object.Tree {
+	t.Helper()
+
+	directory := t.TempDir()
+
+	repository, err := git.PlainInit(directory, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, contents := range files {
+		filename := filepath.Join(directory, name)
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filename, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := worktree.Commit("test", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := repository.CommitObject(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tree
+}
@@ -7,17 +7,53 @@ package matcher //nolint:testpackage
 
 import (
 	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v6/plumbing/object"
 )
 
 // NewTestGitMatcher is a test-only helper function which bridges access to the
 // internal newGitMatcher constructor.
 func NewTestGitMatcher(currentDir string, gitDir string, gitStatus git.Status, pattern string) (Matcher, error) {
+	return NewTestGitWorktreeMatcher(currentDir, gitDir, gitStatus, nil, nil, pattern)
+}
+
+// NewTestGitWorktreeMatcher is a test-only helper function which bridges
+// access to the internal newGitMatcher constructor, along with the given
+// gitignore patterns and changed-since tree.
+func NewTestGitWorktreeMatcher(currentDir string, gitDir string, gitStatus git.Status, ignorePatterns []string, tree *object.Tree, pattern string) (Matcher, error) {
+	patterns := make([]gitignore.Pattern, len(ignorePatterns))
+	for i, ignorePattern := range ignorePatterns {
+		patterns[i] = gitignore.ParsePattern(ignorePattern, nil)
+	}
+
 	return newGitMatcher(
 		func() (string, error) {
 			return currentDir, nil
 		},
-		func() (string, git.Status, error) {
-			return gitDir, gitStatus, nil
+		func() (gitWorktree, error) {
+			return gitWorktree{
+				root:    gitDir,
+				status:  gitStatus,
+				ignored: gitignore.NewMatcher(patterns),
+				tree: func(string) (*object.Tree, error) {
+					return tree, nil
+				},
+			}, nil
+		},
+		pattern,
+	)
+}
+
+// NewTestGitRepositoryMatcher is a test-only helper function which bridges
+// access to the internal newGitMatcher constructor, using the status of the
+// git repository in the given directory.
+func NewTestGitRepositoryMatcher(directory string, pattern string) (Matcher, error) {
+	return newGitMatcher(
+		func() (string, error) {
+			return directory, nil
+		},
+		func() (gitWorktree, error) {
+			return openGitWorktree(directory)
 		},
 		pattern,
 	)
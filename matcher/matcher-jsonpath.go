@@ -8,10 +8,12 @@ package matcher
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/gobwas/glob"
 	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 
 	"github.com/joshdk/krf/resources"
 )
@@ -26,7 +28,10 @@ func NewJsonpathMatcher(selector string) (Matcher, error) {
 		return nil, err
 	}
 
-	m := jsonpathMatcher{keyJsonpath: keyJsonpath}
+	m := jsonpathMatcher{
+		keyJsonpath: keyJsonpath,
+		hasFilter:   strings.Contains(key, "?("),
+	}
 
 	// No target value was given to match against, so we'll only be checking
 	// for the existence of the given jsonpath...path.
@@ -47,6 +52,7 @@ func NewJsonpathMatcher(selector string) (Matcher, error) {
 type jsonpathMatcher struct {
 	keyJsonpath *jsonpath.JSONPath
 	valueGlob   glob.Glob
+	hasFilter   bool
 }
 
 func (m jsonpathMatcher) Matches(item resources.Resource) bool {
@@ -88,6 +94,158 @@ func (m jsonpathMatcher) Matches(item resources.Resource) bool {
 	return false
 }
 
+// Locate returns the original source lines of each field matching the
+// jsonpath (and value, if given). The jsonpath is evaluated against a copy of
+// the original source, where every value is replaced with a placeholder that
+// refers back to the line of that value. As such, jsonpaths containing filter
+// expressions (which would compare against these placeholders) are not
+// located.
+func (m jsonpathMatcher) Locate(item resources.Resource) []int {
+	if m.hasFilter {
+		return nil
+	}
+
+	node, offset := resources.SourceNode(item)
+	if node == nil {
+		return nil
+	}
+
+	var fields sourceFields
+
+	results, err := m.keyJsonpath.FindResults(fields.placeholders(node.YNode(), node.YNode().Line))
+	if err != nil || len(results) == 0 {
+		return nil
+	}
+
+	var lines []int
+
+	for _, value := range results[0] {
+		field, ok := fields.lookup(value.Interface())
+
+		switch {
+		case !ok:
+			continue
+		case m.valueGlob == nil:
+		case !field.scalar:
+			continue
+		case field.null:
+			if !m.valueGlob.Match("nil") && !m.valueGlob.Match("null") {
+				continue
+			}
+		case !m.valueGlob.Match(field.value):
+			continue
+		}
+
+		lines = append(lines, field.line+offset)
+	}
+
+	return lines
+}
+
+// sourceField is a single value in the original source of a resource.
+type sourceField struct {
+	line   int
+	value  string
+	scalar bool
+	null   bool
+}
+
+// sourceFields holds every value in the original source of a resource, which
+// are referred to by placeholders.
+type sourceFields []sourceField
+
+// placeholderPrefix is the prefix of every placeholder, and is also the key
+// used to store the placeholder of each map inside of that map.
+const placeholderPrefix = "\x00"
+
+// placeholders converts the given yaml node into an object, where each value
+// is replaced with a placeholder. The given line is the line of the key that
+// the node is the value of.
+func (f *sourceFields) placeholders(node *yaml.Node, line int) any {
+	placeholder := func(field sourceField) string {
+		*f = append(*f, field)
+
+		return placeholderPrefix + strconv.Itoa(len(*f)-1)
+	}
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		return f.placeholders(node.Alias, line)
+
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+
+		return f.placeholders(node.Content[0], line)
+
+	case yaml.MappingNode:
+		object := map[string]any{placeholderPrefix: placeholder(sourceField{line: line})}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			object[key.Value] = f.placeholders(value, key.Line)
+		}
+
+		return object
+
+	case yaml.SequenceNode:
+		list := make([]any, len(node.Content))
+		for i, child := range node.Content {
+			list[i] = f.placeholders(child, child.Line)
+		}
+
+		return list
+
+	default:
+		return placeholder(sourceField{
+			line:   line,
+			value:  node.Value,
+			scalar: true,
+			null:   node.Tag == yaml.NodeTagNull,
+		})
+	}
+}
+
+// lookup returns the sourceField referred to by the given placeholder value.
+// Maps are resolved using the placeholder stored inside of them, and lists
+// using their first item.
+func (f sourceFields) lookup(value any) (sourceField, bool) {
+	switch v := value.(type) {
+	case string:
+		index, err := strconv.Atoi(strings.TrimPrefix(v, placeholderPrefix))
+		if err != nil || !strings.HasPrefix(v, placeholderPrefix) || !f[index].scalar {
+			// Placeholders stored inside of maps are not themselves fields.
+			return sourceField{}, false
+		}
+
+		return f[index], true
+
+	case map[string]any:
+		placeholder, _ := v[placeholderPrefix].(string)
+
+		index, err := strconv.Atoi(strings.TrimPrefix(placeholder, placeholderPrefix))
+		if err != nil {
+			return sourceField{}, false
+		}
+
+		return f[index], true
+
+	case []any:
+		if len(v) == 0 {
+			return sourceField{}, false
+		}
+
+		field, ok := f.lookup(v[0])
+		field.scalar = false
+
+		return field, ok
+
+	default:
+		return sourceField{}, false
+	}
+}
+
 func newJsonpath(spec string) (*jsonpath.JSONPath, error) {
 	// Do not require that the user include the surrounding '{...}' on the
 	// jsonpath.
@@ -23,6 +23,15 @@ func (m notMatcher) Matches(item resources.Resource) bool {
 	return !m.matcher.Matches(item)
 }
 
+// Prepare prepares the wrapped Matcher instance.
+func (m notMatcher) Prepare(all []resources.Resource) {
+	Prepare(m.matcher, all)
+}
+
+func (m notMatcher) wrapped() []Matcher {
+	return []Matcher{m.matcher}
+}
+
 // AllMatcher wraps a sequence of Matcher instances and returns true if each of
 // the wrapped Matcher instances returns true.
 type AllMatcher struct {
@@ -49,6 +58,28 @@ func (m *AllMatcher) Matches(item resources.Resource) bool {
 	return true
 }
 
+// Prepare prepares each of the wrapped Matcher instances.
+func (m *AllMatcher) Prepare(all []resources.Resource) {
+	for _, matcher := range m.matchers {
+		Prepare(matcher, all)
+	}
+}
+
+func (m *AllMatcher) wrapped() []Matcher {
+	return m.matchers
+}
+
+// Locate returns the lines located by each of the wrapped Matcher instances.
+func (m *AllMatcher) Locate(item resources.Resource) []int {
+	var lines []int
+
+	for _, matcher := range m.matchers {
+		lines = append(lines, Locate(matcher, item)...)
+	}
+
+	return lines
+}
+
 // AnyMatcher wraps a sequence of Matcher instances and returns true if any of
 // the wrapped Matcher instances returns true. An empty AnyMatcher will also
 // return true.
@@ -80,3 +111,28 @@ func (m *AnyMatcher) Matches(item resources.Resource) bool {
 
 	return false
 }
+
+// Prepare prepares each of the wrapped Matcher instances.
+func (m *AnyMatcher) Prepare(all []resources.Resource) {
+	for _, matcher := range m.matchers {
+		Prepare(matcher, all)
+	}
+}
+
+func (m *AnyMatcher) wrapped() []Matcher {
+	return m.matchers
+}
+
+// Locate returns the lines located by each of the wrapped Matcher instances
+// that matched the given resources.Resource.
+func (m *AnyMatcher) Locate(item resources.Resource) []int {
+	var lines []int
+
+	for _, matcher := range m.matchers {
+		if matcher.Matches(item) {
+			lines = append(lines, Locate(matcher, item)...)
+		}
+	}
+
+	return lines
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/joshdk/krf/matcher"
+	"github.com/joshdk/krf/resources"
 )
 
 func TestNotMatcher(t *testing.T) {
@@ -92,3 +93,56 @@ func TestAllMatcher(t *testing.T) {
 		},
 	})
 }
+
+func TestPrepare(t *testing.T) {
+	t.Parallel()
+
+	items := []resources.Resource{
+		newResource("v1", "Secret", "default", "example-secrets"),
+	}
+
+	testMatcher(t, []spec{
+		{
+			title: "nested corpus matchers",
+			matcher: func() matcher.Matcher {
+				am := &matcher.AnyMatcher{}
+				am.Append(must(matcher.NewKindMatcher("svc")))
+				am.Append(matcher.NotMatcher(matcher.NewOrphanedMatcher()))
+
+				chain := &matcher.AllMatcher{}
+				chain.Append(am)
+				chain.Append(matcher.NotMatcher(matcher.NewDanglingReferenceMatcher()))
+
+				return chain
+			}(),
+			items: append(items, testResources...),
+			matches: []string{
+				"Secret/example-secrets",
+				"Service/my-service",
+			},
+		},
+	})
+}
+
+func TestNeedsCorpus(t *testing.T) {
+	t.Parallel()
+
+	simple := &matcher.AllMatcher{}
+	simple.Append(must(matcher.NewKindMatcher("svc")))
+	simple.Append(matcher.NotMatcher(must(matcher.NewNameMatcher("example-*"))))
+
+	nested := &matcher.AllMatcher{}
+	nested.Append(must(matcher.NewKindMatcher("svc")))
+
+	am := &matcher.AnyMatcher{}
+	am.Append(matcher.NotMatcher(matcher.NewOrphanedMatcher()))
+	nested.Append(am)
+
+	if matcher.NeedsCorpus(simple) {
+		t.Error("expected simple matchers to not need the corpus")
+	}
+
+	if !matcher.NeedsCorpus(nested) {
+		t.Error("expected nested corpus matchers to need the corpus")
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher
+
+import (
+	"strings"
+
+	"github.com/joshdk/krf/resources"
+)
+
+// NewPatchedByMatcher matches resources.Resource instances (built from a
+// kustomization) that had a kustomize patch applied from a file path
+// containing the given substring.
+//
+// For example, a resource patched by the file
+// `kustomize/environments/production/replicas.patch.yaml` would be matched by
+// the input `environments/production` or `replicas.patch.yaml`.
+func NewPatchedByMatcher(path string) (Matcher, error) {
+	return patchedByMatcher{path: path}, nil
+}
+
+type patchedByMatcher struct {
+	path string
+}
+
+func (m patchedByMatcher) Matches(item resources.Resource) bool {
+	for _, patch := range item.GetPatches() {
+		if strings.Contains(patch, m.path) {
+			return true
+		}
+	}
+
+	return false
+}
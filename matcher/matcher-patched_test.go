@@ -0,0 +1,59 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/joshdk/krf/matcher"
+	"github.com/joshdk/krf/resources"
+)
+
+func TestPatchedByMatcher(t *testing.T) {
+	t.Parallel()
+
+	var kustomizeResources []resources.Resource
+
+	err := resources.Decode("../resources/testdata/kustomize/overlay", func(item resources.Resource) {
+		kustomizeResources = append(kustomizeResources, item)
+	}, resources.WithKustomize())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testMatcher(t, []spec{
+		{
+			title:   "patch file",
+			matcher: must(matcher.NewPatchedByMatcher("replicas.patch.yaml")),
+			items:   kustomizeResources,
+			matches: []string{
+				"Deployment/prod-backend",
+			},
+		},
+		{
+			title:   "inline patch",
+			matcher: must(matcher.NewPatchedByMatcher("overlay/kustomization.yaml")),
+			items:   kustomizeResources,
+			matches: []string{
+				"Service/prod-backend",
+			},
+		},
+		{
+			title:   "any overlay patch",
+			matcher: must(matcher.NewPatchedByMatcher("overlay")),
+			items:   kustomizeResources,
+			matches: []string{
+				"Deployment/prod-backend",
+				"Service/prod-backend",
+			},
+		},
+		{
+			title:   "not built from a kustomization",
+			matcher: must(matcher.NewPatchedByMatcher("overlay")),
+			matches: []string{},
+		},
+	})
+}
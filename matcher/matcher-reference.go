@@ -21,10 +21,20 @@ import (
 // "cm/my-configmap-*" to match any reference to a resource with that kind and
 // name.
 func NewReferenceMatcher(reference string) (Matcher, error) {
+	kinds, nameGlob, err := splitReference(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	return referenceMatcher{kinds, nameGlob}, nil
+}
+
+// splitReference splits a string like "cm/my-configmap-*" or "my-configmap-*"
+// into a list of resolved kinds (which is empty if no kind was given) and a
+// name glob.
+func splitReference(reference string) ([]string, glob.Glob, error) {
 	var kind, name string
 
-	// Split a string like "cm/my-configmap" or "my-configmap" into the kind
-	// and name.
 	parts := strings.SplitN(reference, "/", 2)
 	if len(parts) == 2 {
 		kind, name = parts[0], parts[1]
@@ -34,7 +44,7 @@ func NewReferenceMatcher(reference string) (Matcher, error) {
 
 	nameGlob, err := asGlob(name)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var kinds []string
@@ -49,7 +59,7 @@ func NewReferenceMatcher(reference string) (Matcher, error) {
 		}
 	}
 
-	return referenceMatcher{kinds, nameGlob}, nil
+	return kinds, nameGlob, nil
 }
 
 type referenceMatcher struct {
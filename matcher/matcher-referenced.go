@@ -0,0 +1,90 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher
+
+import (
+	"slices"
+
+	"github.com/gobwas/glob"
+
+	"github.com/joshdk/krf/references"
+	"github.com/joshdk/krf/resolver"
+	"github.com/joshdk/krf/resources"
+)
+
+// NewReferencedByMatcher matches resources.Resource instances that are
+// referenced by the given named resource. This is the inverse of
+// NewReferenceMatcher. A name glob like "backend-*" can be used to match
+// resources referenced by any resource with that name, or can include a kind
+// like "deploy/backend-*" to match resources referenced by a resource with
+// that kind and name.
+func NewReferencedByMatcher(reference string) (Matcher, error) {
+	kinds, nameGlob, err := splitReference(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	return &referencedByMatcher{kinds: kinds, nameGlob: nameGlob}, nil
+}
+
+type referencedByMatcher struct {
+	kinds    []string
+	nameGlob glob.Glob
+	index    references.Index
+}
+
+func (m *referencedByMatcher) Prepare(all []resources.Resource) {
+	m.index = make(references.Index)
+
+	for _, item := range all {
+		// Skip resources that are not the referencing resource.
+		if len(m.kinds) > 0 && !slices.Contains(m.kinds, item.GetKind()) {
+			continue
+		}
+
+		if !m.nameGlob.Match(item.GetName()) {
+			continue
+		}
+
+		references.All(item.Unstructured, func(kind, name string) {
+			m.index.Add(kind, references.Namespace(item.GetNamespace(), kind), name)
+		})
+	}
+}
+
+func (m *referencedByMatcher) Matches(item resources.Resource) bool {
+	return m.index.Contains(item.GetKind(), item.GetNamespace(), item.GetName())
+}
+
+// NewOrphanedMatcher matches resources.Resource instances that could be
+// referenced by other resources (like a ConfigMap or Secret), but are not
+// referenced by any resource in the corpus of all decoded resources.
+func NewOrphanedMatcher() Matcher {
+	return &orphanedMatcher{}
+}
+
+type orphanedMatcher struct {
+	index references.Index
+}
+
+func (m *orphanedMatcher) Prepare(all []resources.Resource) {
+	m.index = make(references.Index)
+
+	for _, item := range all {
+		references.All(item.Unstructured, func(kind, name string) {
+			m.index.Add(kind, references.Namespace(item.GetNamespace(), kind), name)
+		})
+	}
+}
+
+func (m *orphanedMatcher) Matches(item resources.Resource) bool {
+	// Ignore resources that can never be referenced to begin with.
+	if !resolver.IsReferenced(item.GetKind()) {
+		return false
+	}
+
+	return !m.index.Contains(item.GetKind(), item.GetNamespace(), item.GetName())
+}
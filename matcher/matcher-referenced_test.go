@@ -0,0 +1,75 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/joshdk/krf/matcher"
+	"github.com/joshdk/krf/resources"
+)
+
+// referencedResources returns the shared set of decoded resources, along with
+// several resources that are referenced by them.
+func referencedResources() []resources.Resource {
+	items := []resources.Resource{
+		newResource("v1", "Secret", "default", "example-secrets"),
+		newResource("v1", "Secret", "default", "unused-secrets"),
+		newResource("v1", "ConfigMap", "custom-app-pr1234", "example-config"),
+		newResource("rbac.authorization.k8s.io/v1", "ClusterRole", "", "secret-reader"),
+	}
+
+	return append(items, testResources...)
+}
+
+func TestReferencedByMatcher(t *testing.T) {
+	t.Parallel()
+
+	testMatcher(t, []spec{
+		{
+			title:   "kind and name",
+			matcher: must(matcher.NewReferencedByMatcher("deploy/nginx-deployment")),
+			items:   referencedResources(),
+			matches: []string{
+				"Secret/example-secrets",
+			},
+		},
+		{
+			title:   "name glob",
+			matcher: must(matcher.NewReferencedByMatcher("*")),
+			items:   referencedResources(),
+			matches: []string{
+				"ClusterRole/secret-reader",
+				"ConfigMap/example-config",
+				"Secret/example-secrets",
+			},
+		},
+		{
+			title:   "wrong kind",
+			matcher: must(matcher.NewReferencedByMatcher("svc/nginx-deployment")),
+			items:   referencedResources(),
+			matches: []string{},
+		},
+	})
+}
+
+func TestOrphanedMatcher(t *testing.T) {
+	t.Parallel()
+
+	testMatcher(t, []spec{
+		{
+			title:   "orphaned",
+			matcher: matcher.NewOrphanedMatcher(),
+			items:   referencedResources(),
+			matches: []string{
+				"ConfigMap/my-configmap",
+				"Deployment/nginx-deployment",
+				"Secret/unused-secrets",
+				"Service/my-service",
+			},
+		},
+	})
+}
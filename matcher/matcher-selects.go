@@ -0,0 +1,145 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher
+
+import (
+	"slices"
+
+	"github.com/gobwas/glob"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/joshdk/krf/references"
+	"github.com/joshdk/krf/resources"
+)
+
+// NewSelectsMatcher matches resources.Resource instances (like a Service or
+// PodDisruptionBudget) that select the given named workload by its pod
+// labels, or in the case of a HorizontalPodAutoscaler, by its name. A name
+// glob like "backend-*" can be used, or can include a kind like
+// "deploy/backend-*".
+func NewSelectsMatcher(reference string) (Matcher, error) {
+	kinds, nameGlob, err := splitReference(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	return &selectsMatcher{kinds: kinds, nameGlob: nameGlob}, nil
+}
+
+type selectsMatcher struct {
+	kinds     []string
+	nameGlob  glob.Glob
+	workloads []resources.Resource
+}
+
+func (m *selectsMatcher) Prepare(all []resources.Resource) {
+	m.workloads = selectable(all, m.kinds, m.nameGlob, references.IsWorkload)
+}
+
+func (m *selectsMatcher) Matches(item resources.Resource) bool {
+	return slices.ContainsFunc(m.workloads, func(workload resources.Resource) bool {
+		return references.Selects(item.Unstructured, workload.Unstructured)
+	})
+}
+
+// NewSelectedByMatcher matches workload resources.Resource instances that are
+// selected by the given named resource (like a Service or
+// PodDisruptionBudget). This is the inverse of NewSelectsMatcher.
+func NewSelectedByMatcher(reference string) (Matcher, error) {
+	kinds, nameGlob, err := splitReference(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	return &selectedByMatcher{kinds: kinds, nameGlob: nameGlob}, nil
+}
+
+type selectedByMatcher struct {
+	kinds     []string
+	nameGlob  glob.Glob
+	selectors []resources.Resource
+}
+
+func (m *selectedByMatcher) Prepare(all []resources.Resource) {
+	m.selectors = selectable(all, m.kinds, m.nameGlob, references.IsSelector)
+}
+
+func (m *selectedByMatcher) Matches(item resources.Resource) bool {
+	return slices.ContainsFunc(m.selectors, func(selector resources.Resource) bool {
+		return references.Selects(selector.Unstructured, item.Unstructured)
+	})
+}
+
+// NewSelectsNothingMatcher matches resources.Resource instances with a
+// selector (like a Service or NetworkPolicy) that does not select any workload
+// in the corpus of all decoded resources.
+func NewSelectsNothingMatcher() Matcher {
+	return &selectsNothingMatcher{}
+}
+
+type selectsNothingMatcher struct {
+	workloads []resources.Resource
+}
+
+func (m *selectsNothingMatcher) Prepare(all []resources.Resource) {
+	m.workloads = selectable(all, nil, nil, references.IsWorkload)
+}
+
+func (m *selectsNothingMatcher) Matches(item resources.Resource) bool {
+	if !references.IsSelector(item.Unstructured) {
+		return false
+	}
+
+	return !slices.ContainsFunc(m.workloads, func(workload resources.Resource) bool {
+		return references.Selects(item.Unstructured, workload.Unstructured)
+	})
+}
+
+// NewNoServiceMatcher matches workload resources.Resource instances that are
+// not selected by any Service in the corpus of all decoded resources.
+func NewNoServiceMatcher() Matcher {
+	return &noServiceMatcher{}
+}
+
+type noServiceMatcher struct {
+	services []resources.Resource
+}
+
+func (m *noServiceMatcher) Prepare(all []resources.Resource) {
+	m.services = selectable(all, []string{"Service"}, nil, references.IsSelector)
+}
+
+func (m *noServiceMatcher) Matches(item resources.Resource) bool {
+	if !references.IsWorkload(item.Unstructured) {
+		return false
+	}
+
+	return !slices.ContainsFunc(m.services, func(service resources.Resource) bool {
+		return references.Selects(service.Unstructured, item.Unstructured)
+	})
+}
+
+// selectable returns the resources from the given list that satisfy the given
+// predicate, and optionally have one of the given kinds and a name matching
+// the given glob.
+func selectable(all []resources.Resource, kinds []string, nameGlob glob.Glob, predicate func(unstructured.Unstructured) bool) []resources.Resource {
+	var results []resources.Resource
+
+	for _, item := range all {
+		switch {
+		case len(kinds) > 0 && !slices.Contains(kinds, item.GetKind()):
+			continue
+		case nameGlob != nil && !nameGlob.Match(item.GetName()):
+			continue
+		case !predicate(item.Unstructured):
+			continue
+		}
+
+		results = append(results, item)
+	}
+
+	return results
+}
@@ -0,0 +1,160 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/joshdk/krf/matcher"
+	"github.com/joshdk/krf/resources"
+)
+
+// selectorResources returns a set of workloads, along with several resources
+// that select them (or fail to).
+func selectorResources() []resources.Resource {
+	withSpec := func(item resources.Resource, spec map[string]any) resources.Resource {
+		item.Object["spec"] = spec
+
+		return item
+	}
+
+	podTemplate := func(labels map[string]any) map[string]any {
+		return map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{"labels": labels},
+			},
+		}
+	}
+
+	return []resources.Resource{
+		withSpec(newResource("apps/v1", "Deployment", "default", "backend"),
+			podTemplate(map[string]any{"app": "backend", "tier": "api"})),
+		withSpec(newResource("apps/v1", "StatefulSet", "default", "database"),
+			podTemplate(map[string]any{"app": "database"})),
+		withSpec(newResource("apps/v1", "DaemonSet", "default", "logger"),
+			podTemplate(map[string]any{"app": "logger"})),
+		withSpec(newResource("v1", "Service", "default", "backend"),
+			map[string]any{"selector": map[string]any{"app": "backend"}}),
+		withSpec(newResource("v1", "Service", "default", "database"),
+			map[string]any{"selector": map[string]any{"app": "database"}}),
+		withSpec(newResource("v1", "Service", "default", "legacy"),
+			map[string]any{"selector": map[string]any{"app": "legacy"}}),
+		withSpec(newResource("v1", "Service", "other", "backend"),
+			map[string]any{"selector": map[string]any{"app": "backend"}}),
+		withSpec(newResource("v1", "Service", "default", "external"),
+			map[string]any{"type": "ExternalName", "externalName": "example.com"}),
+		withSpec(newResource("policy/v1", "PodDisruptionBudget", "default", "backend"),
+			map[string]any{"selector": map[string]any{
+				"matchExpressions": []any{
+					map[string]any{"key": "tier", "operator": "In", "values": []any{"api"}},
+				},
+			}}),
+		withSpec(newResource("networking.k8s.io/v1", "NetworkPolicy", "default", "default-deny"),
+			map[string]any{"podSelector": map[string]any{}}),
+		withSpec(newResource("autoscaling/v2", "HorizontalPodAutoscaler", "default", "backend"),
+			map[string]any{"scaleTargetRef": map[string]any{"apiVersion": "apps/v1", "kind": "Deployment", "name": "backend"}}),
+		withSpec(newResource("autoscaling/v2", "HorizontalPodAutoscaler", "default", "frontend"),
+			map[string]any{"scaleTargetRef": map[string]any{"apiVersion": "apps/v1", "kind": "Deployment", "name": "frontend"}}),
+	}
+}
+
+func TestSelectsMatcher(t *testing.T) {
+	t.Parallel()
+
+	testMatcher(t, []spec{
+		{
+			title:   "kind and name",
+			matcher: must(matcher.NewSelectsMatcher("deploy/backend")),
+			items:   selectorResources(),
+			matches: []string{
+				"HorizontalPodAutoscaler/backend",
+				"NetworkPolicy/default-deny",
+				"PodDisruptionBudget/backend",
+				"Service/backend",
+			},
+		},
+		{
+			title:   "name glob",
+			matcher: must(matcher.NewSelectsMatcher("data*")),
+			items:   selectorResources(),
+			matches: []string{
+				"NetworkPolicy/default-deny",
+				"Service/database",
+			},
+		},
+		{
+			title:   "wrong kind",
+			matcher: must(matcher.NewSelectsMatcher("svc/backend")),
+			items:   selectorResources(),
+			matches: []string{},
+		},
+	})
+}
+
+func TestSelectedByMatcher(t *testing.T) {
+	t.Parallel()
+
+	testMatcher(t, []spec{
+		{
+			title:   "service",
+			matcher: must(matcher.NewSelectedByMatcher("svc/backend")),
+			items:   selectorResources(),
+			matches: []string{
+				"Deployment/backend",
+			},
+		},
+		{
+			title:   "network policy",
+			matcher: must(matcher.NewSelectedByMatcher("netpol/default-deny")),
+			items:   selectorResources(),
+			matches: []string{
+				"DaemonSet/logger",
+				"Deployment/backend",
+				"StatefulSet/database",
+			},
+		},
+		{
+			title:   "horizontal pod autoscaler",
+			matcher: must(matcher.NewSelectedByMatcher("hpa/*")),
+			items:   selectorResources(),
+			matches: []string{
+				"Deployment/backend",
+			},
+		},
+	})
+}
+
+func TestSelectsNothingMatcher(t *testing.T) {
+	t.Parallel()
+
+	testMatcher(t, []spec{
+		{
+			title:   "selects nothing",
+			matcher: matcher.NewSelectsNothingMatcher(),
+			items:   selectorResources(),
+			matches: []string{
+				"HorizontalPodAutoscaler/frontend",
+				"Service/backend",
+				"Service/legacy",
+			},
+		},
+	})
+}
+
+func TestNoServiceMatcher(t *testing.T) {
+	t.Parallel()
+
+	testMatcher(t, []spec{
+		{
+			title:   "no service",
+			matcher: matcher.NewNoServiceMatcher(),
+			items:   selectorResources(),
+			matches: []string{
+				"DaemonSet/logger",
+			},
+		},
+	})
+}
@@ -0,0 +1,32 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher
+
+import (
+	"github.com/gobwas/glob"
+
+	"github.com/joshdk/krf/resources"
+)
+
+// NewSourceMatcher matches resources.Resource instances based on the label of
+// the source from which they were decoded (like "prod" from the source
+// "prod=./dump-prod.yaml").
+func NewSourceMatcher(source string) (Matcher, error) {
+	sourceGlob, err := asGlob(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return sourceMatcher{sourceGlob: sourceGlob}, nil
+}
+
+type sourceMatcher struct {
+	sourceGlob glob.Glob
+}
+
+func (m sourceMatcher) Matches(item resources.Resource) bool {
+	return m.sourceGlob.Match(item.GetSource())
+}
@@ -0,0 +1,49 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/joshdk/krf/matcher"
+	"github.com/joshdk/krf/resources"
+)
+
+func TestSourceMatcher(t *testing.T) {
+	t.Parallel()
+
+	var items []resources.Resource
+
+	for source, filename := range map[string]string{
+		"prod":  "testdata/service.yaml",
+		"stage": "testdata/subdir/deployment.yaml",
+	} {
+		if err := resources.Decode(filename, func(item resources.Resource) {
+			items = append(items, item)
+		}, resources.WithSource(source)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testMatcher(t, []spec{
+		{
+			title:   "exact source",
+			matcher: must(matcher.NewSourceMatcher("prod")),
+			matches: []string{"Service/my-service"},
+			items:   items,
+		},
+		{
+			title:   "source glob",
+			matcher: must(matcher.NewSourceMatcher("*")),
+			matches: []string{"Service/my-service", "Deployment/nginx-deployment"},
+			items:   items,
+		},
+		{
+			title:   "unlabelled resources",
+			matcher: must(matcher.NewSourceMatcher("prod")),
+		},
+	})
+}
@@ -8,12 +8,55 @@
 // on user input.
 package matcher
 
-import "github.com/joshdk/krf/resources"
+import (
+	"slices"
+
+	"github.com/joshdk/krf/resources"
+)
 
 // Matcher represents the logic for matching against the properties of a
 // resources.Resource based on some inputs.
 type Matcher interface {
 	// Matches returns true if the given resources.Resource object matches based
-	// on the logic and inputs for a concrete matcher.
+	// on the logic and inputs for a concrete matcher. Must be safe to call
+	// concurrently, as resources are matched in parallel.
 	Matches(item resources.Resource) bool
 }
+
+// CorpusMatcher represents a Matcher which needs to examine the entire corpus
+// of resources.Resource objects before matching individual resources. For
+// example, determining if a resource references another resource that does
+// not exist.
+type CorpusMatcher interface {
+	Matcher
+
+	// Prepare is given every decoded resources.Resource object, and is called
+	// once before any calls to Matches.
+	Prepare(all []resources.Resource)
+}
+
+// Prepare calls Prepare on the given Matcher if it is a CorpusMatcher, and
+// does nothing otherwise.
+func Prepare(matcher Matcher, all []resources.Resource) {
+	if cm, ok := matcher.(CorpusMatcher); ok {
+		cm.Prepare(all)
+	}
+}
+
+// wrapper represents a Matcher which wraps other Matcher instances.
+type wrapper interface {
+	wrapped() []Matcher
+}
+
+// NeedsCorpus reports if the given Matcher (or any Matcher that it wraps) is
+// a CorpusMatcher, and so needs to examine the entire corpus of resources
+// before matching individual resources.
+func NeedsCorpus(matcher Matcher) bool {
+	if w, ok := matcher.(wrapper); ok {
+		return slices.ContainsFunc(w.wrapped(), NeedsCorpus)
+	}
+
+	_, ok := matcher.(CorpusMatcher)
+
+	return ok
+}
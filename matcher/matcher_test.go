@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
 	"github.com/joshdk/krf/config"
 	"github.com/joshdk/krf/matcher"
 	"github.com/joshdk/krf/resolver"
@@ -42,9 +44,13 @@ type spec struct {
 	title   string
 	matcher matcher.Matcher
 	matches []string
+
+	// items optionally replaces the shared set of decoded resources.
+	items []resources.Resource
 }
 
-// testMatcher evaluates all given specs against the shared set of decoded resources.
+// testMatcher evaluates all given specs against the shared set of decoded
+// resources, or against the resources given by an individual spec.
 func testMatcher(t *testing.T, tests []spec) {
 	t.Helper()
 
@@ -60,7 +66,14 @@ func testMatcher(t *testing.T, tests []spec) {
 
 			actual := make(map[string]struct{})
 
-			for _, item := range testResources {
+			items := testResources
+			if test.items != nil {
+				items = test.items
+			}
+
+			matcher.Prepare(test.matcher, items)
+
+			for _, item := range items {
 				if test.matcher.Matches(item) {
 					name := fmt.Sprintf("%s/%s", item.GetKind(), item.GetName())
 					actual[name] = struct{}{}
@@ -86,6 +99,19 @@ func testMatcher(t *testing.T, tests []spec) {
 	}
 }
 
+// newResource returns a minimal resource, for tests that need resources
+// beyond the shared set of decoded resources.
+func newResource(apiVersion, kind, namespace, name string) resources.Resource {
+	return resources.Resource{Unstructured: unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": namespace,
+		},
+	}}}
+}
+
 func must(m matcher.Matcher, err error) matcher.Matcher {
 	if err != nil {
 		panic(err)
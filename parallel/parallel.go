@@ -0,0 +1,101 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+// Package parallel provides a bounded worker pool which processes values
+// concurrently, while still emitting the results in their original order.
+package parallel
+
+import (
+	"runtime"
+)
+
+// Ordered processes each submitted value concurrently, and emits each result
+// in the same order that the values were submitted.
+type Ordered[T, R any] struct {
+	// jobs holds each submitted value that has not yet been processed, along
+	// with the channel for its result.
+	jobs chan job[T, R]
+
+	// pending holds a channel for the result of each submitted value that
+	// has not yet been emitted. Its capacity bounds how many values are
+	// processed (or buffered) at once.
+	pending chan chan R
+	done    chan struct{}
+}
+
+// job is a single submitted value, along with the channel for its result.
+type job[T, R any] struct {
+	value  T
+	result chan R
+}
+
+// New returns an Ordered pool which calls the given fn function with each
+// submitted value, using at most the given number of workers at once. The
+// given emit function is called with each result, in order, from a single
+// goroutine. If the number of workers is 0, the number of CPUs is used.
+func New[T, R any](workers int, fn func(T) R, emit func(R)) *Ordered[T, R] {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	o := &Ordered[T, R]{
+		jobs:    make(chan job[T, R], workers),
+		pending: make(chan chan R, workers),
+		done:    make(chan struct{}),
+	}
+
+	for range workers {
+		go func() {
+			for job := range o.jobs {
+				job.result <- fn(job.value)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(o.done)
+
+		for result := range o.pending {
+			emit(<-result)
+		}
+	}()
+
+	return o
+}
+
+// Submit schedules the given value to be processed. Blocks while the maximum
+// number of values are already being processed, or are waiting to be emitted.
+func (o *Ordered[T, R]) Submit(value T) {
+	result := make(chan R, 1)
+	o.pending <- result
+	o.jobs <- job[T, R]{value: value, result: result}
+}
+
+// Wait waits for every submitted value to be processed and emitted. No more
+// values can be submitted afterwards.
+func (o *Ordered[T, R]) Wait() {
+	close(o.jobs)
+	close(o.pending)
+	<-o.done
+}
+
+// Map calls the given fn function with each of the given values concurrently,
+// using at most the given number of workers at once, and returns the results
+// in the same order as the values.
+func Map[T, R any](workers int, values []T, fn func(T) R) []R {
+	results := make([]R, 0, len(values))
+
+	pool := New(workers, fn, func(result R) {
+		results = append(results, result)
+	})
+
+	for _, value := range values {
+		pool.Submit(value)
+	}
+
+	pool.Wait()
+
+	return results
+}
@@ -0,0 +1,77 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package parallel_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joshdk/krf/parallel"
+)
+
+func TestOrdered(t *testing.T) {
+	t.Parallel()
+
+	const workers = 4
+
+	var (
+		running atomic.Int32
+		peak    atomic.Int32
+		results []int
+	)
+
+	pool := parallel.New(workers, func(value int) int {
+		current := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+
+		// Finish each value in a random order.
+		time.Sleep(time.Duration(rand.IntN(1000)) * time.Microsecond) //nolint:gosec
+
+		return value * 2
+	}, func(result int) {
+		results = append(results, result)
+	})
+
+	var expected []int
+
+	for value := range 100 {
+		pool.Submit(value)
+
+		expected = append(expected, value*2)
+	}
+
+	pool.Wait()
+
+	if !slices.Equal(expected, results) {
+		t.Errorf("expected results %v, got %v", expected, results)
+	}
+
+	if peak.Load() > workers {
+		t.Errorf("expected at most %d concurrent workers, got %d", workers, peak.Load())
+	}
+}
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+
+	results := parallel.Map(0, []string{"a", "b", "c"}, func(value string) string {
+		return value + value
+	})
+
+	if expected := []string{"aa", "bb", "cc"}; !slices.Equal(expected, results) {
+		t.Errorf("expected results %v, got %v", expected, results)
+	}
+}
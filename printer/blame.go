@@ -0,0 +1,65 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package printer
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rodaine/table"
+
+	"github.com/joshdk/krf/resources"
+)
+
+// Blame prints each given resources.Resource as a row in a formatted table,
+// along with the author, date, and commit (according to git blame) of the
+// most recent change to any of the lines occupied by that resource.
+func Blame(w io.Writer, items []resources.Resource) error {
+	tbl := table.New("Namespace", "Kind", "Name", "Author", "Modified", "Commit", "Path")
+	tbl.WithHeaderSeparatorRow('─')
+	tbl.WithWriter(w)
+
+	for _, item := range items {
+		lines, err := resources.Blame(item)
+		if err != nil {
+			return fmt.Errorf("%s: %w", item.GetFilename(), err)
+		}
+
+		var author, modified, commit, path string
+
+		if len(lines) > 0 {
+			// Find the most recently modified line.
+			latest := lines[0]
+			for _, line := range lines[1:] {
+				if line.Date.After(latest.Date) {
+					latest = line
+				}
+			}
+
+			author = latest.Author
+			modified = latest.Date.Format(time.DateOnly)
+
+			if len(latest.Commit) > 7 { //nolint:mnd
+				commit = latest.Commit[:7]
+			}
+		}
+
+		if item.GetFilename() != "" {
+			path = item.GetFilename()
+
+			if item.GetLine() != 0 {
+				path += fmt.Sprintf(":%d-%d", item.GetLine(), item.GetEndLine())
+			}
+		}
+
+		tbl.AddRow(item.GetNamespace(), item.GetKind(), item.GetName(), author, modified, commit, path)
+	}
+
+	tbl.Print()
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package printer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/rodaine/table"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/joshdk/krf/resources"
+)
+
+// CustomColumns returns a printer function which prints each given
+// resources.Resource as a row in a formatted table, with columns given in the
+// same format as kubectl (like "NAME:.metadata.name,IMAGE:.spec.image"). Fields
+// that are missing are printed as "<none>", and fields with multiple values
+// are joined by commas.
+func CustomColumns(spec string) (func(io.Writer, []resources.Resource) error, error) {
+	if spec == "" {
+		return nil, errors.New("custom-columns format specified but no custom columns given")
+	}
+
+	var (
+		headers []any
+		paths   []*jsonpath.JSONPath
+	)
+
+	for _, column := range strings.Split(spec, ",") {
+		header, expression, found := strings.Cut(column, ":")
+		if !found || header == "" {
+			return nil, fmt.Errorf("unexpected custom-columns spec: %q, expected <header>:<json-path-expr>", column)
+		}
+
+		path, err := newColumnJSONPath(expression)
+		if err != nil {
+			return nil, fmt.Errorf("parsing custom column %q: %w", header, err)
+		}
+
+		headers = append(headers, header)
+		paths = append(paths, path)
+	}
+
+	return func(w io.Writer, items []resources.Resource) error {
+		tbl := table.New(headers...)
+		tbl.WithHeaderSeparatorRow('─')
+		tbl.WithWriter(w)
+
+		for _, item := range items {
+			row := make([]any, len(paths))
+
+			for index, path := range paths {
+				results, err := path.FindResults(item.Object)
+				if err != nil {
+					return err
+				}
+
+				var values []string
+
+				if len(results) == 0 || len(results[0]) == 0 {
+					values = append(values, "<none>")
+				}
+
+				for _, result := range results {
+					for _, value := range result {
+						values = append(values, fmt.Sprint(value.Interface()))
+					}
+				}
+
+				row[index] = strings.Join(values, ",")
+			}
+
+			tbl.AddRow(row...)
+		}
+
+		tbl.Print()
+
+		return nil
+	}, nil
+}
+
+// customColumnsFile returns a CustomColumns printer function from the given
+// file contents, in the same format as kubectl. The first line holds the
+// column headers, and the second line holds the jsonpath of each column, each
+// separated by whitespace.
+func customColumnsFile(contents string) (func(io.Writer, []resources.Resource) error, error) {
+	lines := strings.Split(strings.TrimSpace(contents), "\n")
+	if len(lines) != 2 { //nolint:mnd
+		return nil, fmt.Errorf("unexpected custom-columns file: expected 2 lines, got %d", len(lines))
+	}
+
+	headers, paths := strings.Fields(lines[0]), strings.Fields(lines[1])
+	if len(headers) != len(paths) {
+		return nil, fmt.Errorf("unexpected custom-columns file: %d headers but %d jsonpaths", len(headers), len(paths))
+	}
+
+	columns := make([]string, len(headers))
+	for index := range headers {
+		columns[index] = headers[index] + ":" + paths[index]
+	}
+
+	return CustomColumns(strings.Join(columns, ","))
+}
+
+// relaxedJSONPathPattern matches a single jsonpath expression, optionally
+// surrounded by braces and without a leading dot (like "metadata.name").
+var relaxedJSONPathPattern = regexp.MustCompile(`^\{\.?([^{}]+)\}$|^\.?([^{}]+)$`)
+
+// newColumnJSONPath parses the given jsonpath expression. As with kubectl, the
+// expression does not require the surrounding braces or leading dot, and
+// missing keys are allowed.
+func newColumnJSONPath(text string) (*jsonpath.JSONPath, error) {
+	if groups := relaxedJSONPathPattern.FindStringSubmatch(text); groups != nil {
+		expression := groups[1]
+		if expression == "" {
+			expression = groups[2]
+		}
+
+		text = "{." + expression + "}"
+	}
+
+	path := jsonpath.New("column").AllowMissingKeys(true)
+
+	return path, path.Parse(text)
+}
@@ -0,0 +1,69 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package printer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rodaine/table"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/joshdk/krf/references"
+	"github.com/joshdk/krf/resources"
+)
+
+// Dangling prints each reference from the given resources.Resource list to a
+// resource that is not present in the given corpus, as a row in a formatted
+// table. An error is returned if any such references were found, so that the
+// report can be used to fail a CI pipeline.
+func Dangling(w io.Writer, corpus []resources.Resource, results []resources.Resource) error {
+	items := make([]unstructured.Unstructured, len(corpus))
+	for i, item := range corpus {
+		items[i] = item.Unstructured
+	}
+
+	index := references.NewIndex(items)
+
+	headers := []any{"Namespace", "Resource", "Missing Reference"}
+
+	// Check if any of the resources were decoded from a file opposed to from
+	// e.g. stdin.
+	for _, item := range results {
+		if item.GetFilename() != "" {
+			headers = append(headers, "Path")
+
+			break
+		}
+	}
+
+	tbl := table.New(headers...)
+	tbl.WithHeaderSeparatorRow('─')
+	tbl.WithWriter(w)
+
+	var count int
+
+	for _, item := range results {
+		index.Dangling(item.Unstructured, func(kind, name string) {
+			count++
+
+			tbl.AddRow(
+				item.GetNamespace(),
+				fmt.Sprintf("%s/%s", item.GetKind(), item.GetName()),
+				fmt.Sprintf("%s/%s", kind, name),
+				item.GetFilename(),
+			)
+		})
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	tbl.Print()
+
+	return fmt.Errorf("found %d dangling references", count)
+}
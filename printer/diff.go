@@ -0,0 +1,201 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/joshdk/krf/diff"
+	"github.com/joshdk/krf/resources"
+)
+
+// ANSI escape sequences used to colorize diff output.
+const (
+	colorReset = "\x1b[0m"
+	colorBold  = "\x1b[1m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+)
+
+// Diff prints how each given resources.Resource differs from its counterpart
+// in the given baseline (as paired and compared using the given diff.Options),
+// as a list of added, removed, and changed fields followed by a unified diff of
+// the resource yaml. Resources that are identical to their counterpart are
+// omitted, and resources that were marked as removed are printed in their
+// entirety.
+//
+// Output is colorized when written directly to a terminal.
+func Diff(w io.Writer, options diff.Options, baseline []resources.Resource, results []resources.Resource) error {
+	p := diffPrinter{w: w, options: options}
+
+	// Only colorize output when it is being sent directly to the terminal.
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		p.color = true
+	}
+
+	// Index the baseline by identity, keeping the first original for each, so
+	// that counterparts can be found without a linear scan.
+	originals := make(map[string]int, len(baseline))
+
+	for index, original := range baseline {
+		key := options.Identity.OriginalKey(original.Unstructured)
+		if _, found := originals[key]; !found {
+			originals[key] = index
+		}
+	}
+
+	for _, item := range results {
+		if item.IsRemoved() {
+			if err := p.print(diff.Removed, item, &item, nil); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		index, found := originals[options.Identity.Key(item.Unstructured)]
+		if !found {
+			if err := p.print(diff.Added, item, nil, &item); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := p.print(diff.Changed, item, &baseline[index], &item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diffPrinter prints the differences for individual resources.
+type diffPrinter struct {
+	w       io.Writer
+	color   bool
+	options diff.Options
+}
+
+// print prints the differences between the given original and modified
+// versions of the given resource, either of which may be nil. Nothing is
+// printed if they are identical.
+func (p diffPrinter) print(status diff.Status, item resources.Resource, before, after *resources.Resource) error {
+	var changes []diff.Change
+	if status == diff.Changed {
+		if changes = p.options.Changes(before.Object, after.Object); len(changes) == 0 {
+			return nil
+		}
+	}
+
+	name := fmt.Sprintf("%s/%s", item.GetKind(), item.GetName())
+	if item.GetNamespace() != "" {
+		name += fmt.Sprintf(" (%s)", item.GetNamespace())
+	}
+
+	p.line(colorBold, fmt.Sprintf("%s %s", diffSymbol(status), name))
+
+	for _, change := range changes {
+		switch change.Type {
+		case diff.Added:
+			p.line(colorGreen, fmt.Sprintf("  + %s: %s", change.Path, diffValue(change.After)))
+		case diff.Removed:
+			p.line(colorRed, fmt.Sprintf("  - %s: %s", change.Path, diffValue(change.Before)))
+		case diff.Changed:
+			p.line(colorCyan, fmt.Sprintf("  ~ %s: %s → %s", change.Path, diffValue(change.Before), diffValue(change.After)))
+		}
+	}
+
+	// Ignored fields are also omitted from the unified diff.
+	unified, err := diff.Unified(
+		p.options.Ignore.Normalize(diffObject(before)),
+		p.options.Ignore.Normalize(diffObject(after)),
+		diffName(before),
+		diffName(after),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(unified, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			p.line(colorBold, line)
+		case strings.HasPrefix(line, "+"):
+			p.line(colorGreen, line)
+		case strings.HasPrefix(line, "-"):
+			p.line(colorRed, line)
+		case strings.HasPrefix(line, "@@"):
+			p.line(colorCyan, line)
+		default:
+			p.line("", line)
+		}
+	}
+
+	p.line("", "")
+
+	return nil
+}
+
+// line prints a single line, in the given color if colorized output is
+// enabled.
+func (p diffPrinter) line(color string, text string) {
+	if p.color && color != "" {
+		fmt.Fprintf(p.w, "%s%s%s\n", color, text, colorReset)
+	} else {
+		fmt.Fprintln(p.w, text)
+	}
+}
+
+// diffObject returns the object of the given resource, if any.
+func diffObject(item *resources.Resource) map[string]any {
+	if item == nil {
+		return nil
+	}
+
+	return item.Object
+}
+
+// diffName returns the name used to label one side of a unified diff.
+func diffName(item *resources.Resource) string {
+	switch {
+	case item == nil:
+		return "/dev/null"
+	case item.GetFilename() != "":
+		return item.GetFilename()
+	default:
+		return fmt.Sprintf("%s/%s", item.GetKind(), item.GetName())
+	}
+}
+
+// diffSymbol returns the symbol used to represent the given status.
+func diffSymbol(status diff.Status) string {
+	switch status {
+	case diff.Added:
+		return "+"
+	case diff.Removed:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// diffValue formats a single field value for display.
+func diffValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,167 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/joshdk/krf/resources"
+)
+
+// DefaultDirectoryTemplate is the default template used to name the file of
+// each resource written by the Directory printer.
+const DefaultDirectoryTemplate = "{{.namespace}}/{{.kind}}-{{.name}}.yaml"
+
+// DirectoryOptions configures the Directory printer.
+type DirectoryOptions struct {
+	// Directory is where each resource file is written.
+	Directory string
+
+	// Template names the file (relative to the directory) of each resource.
+	// The template is passed the name, namespace, kind (lowercased),
+	// apiVersion, group, version, and source label of each resource.
+	Template string
+
+	// Kustomization additionally writes a kustomization.yaml file listing
+	// every resource file.
+	Kustomization bool
+}
+
+// Directory returns a printer function which writes each given
+// resources.Resource to its own file within a directory, and then prints the
+// path of each file written. Resources whose files are named the same are
+// written to that file as a stream of yaml documents.
+func Directory(options DirectoryOptions) (func(io.Writer, []resources.Resource) error, error) {
+	if options.Template == "" {
+		options.Template = DefaultDirectoryTemplate
+	}
+
+	tmpl, err := template.New("filename").Option("missingkey=error").Parse(options.Template)
+	if err != nil {
+		return nil, fmt.Errorf("parsing filename template: %w", err)
+	}
+
+	return func(w io.Writer, results []resources.Resource) error {
+		// Group the resources by the file each is written to, preserving the
+		// original resource order.
+		var filenames []string
+
+		files := make(map[string][]resources.Resource)
+
+		for _, item := range results {
+			filename, err := directoryFilename(tmpl, item)
+			if err != nil {
+				return err
+			}
+
+			if _, found := files[filename]; !found {
+				filenames = append(filenames, filename)
+			}
+
+			files[filename] = append(files[filename], item)
+		}
+
+		if options.Kustomization {
+			if _, found := files["kustomization.yaml"]; found {
+				return errors.New("resource file kustomization.yaml conflicts with the generated kustomization")
+			}
+		}
+
+		for _, filename := range filenames {
+			if err := writeFile(filepath.Join(options.Directory, filename), files[filename]); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(w, filepath.Join(options.Directory, filename))
+		}
+
+		if !options.Kustomization {
+			return nil
+		}
+
+		slices.Sort(filenames)
+
+		kustomization, err := yaml.Marshal(map[string]any{
+			"apiVersion": "kustomize.config.k8s.io/v1beta1",
+			"kind":       "Kustomization",
+			"resources":  filenames,
+		})
+		if err != nil {
+			return err
+		}
+
+		filename := filepath.Join(options.Directory, "kustomization.yaml")
+
+		if err := os.WriteFile(filename, kustomization, 0o644); err != nil { //nolint:gosec,mnd
+			return err
+		}
+
+		fmt.Fprintln(w, filename)
+
+		return nil
+	}, nil
+}
+
+// directoryFilename executes the given template for the given resource, and
+// returns the resulting (slash separated) filename. The filename must remain
+// within the output directory.
+func directoryFilename(tmpl *template.Template, item resources.Resource) (string, error) {
+	gvk := item.GroupVersionKind()
+
+	var buffer bytes.Buffer
+
+	if err := tmpl.Execute(&buffer, map[string]string{
+		"name":       item.GetName(),
+		"namespace":  item.GetNamespace(),
+		"kind":       strings.ToLower(item.GetKind()),
+		"apiVersion": item.GetAPIVersion(),
+		"group":      gvk.Group,
+		"version":    gvk.Version,
+		"source":     item.GetSource(),
+	}); err != nil {
+		return "", fmt.Errorf("executing filename template: %w", err)
+	}
+
+	// Empty template fields (like the namespace of a cluster scoped resource)
+	// can produce leading or repeated slashes.
+	filename := strings.TrimLeft(filepath.ToSlash(filepath.Clean(buffer.String())), "/")
+
+	if !filepath.IsLocal(filename) {
+		return "", fmt.Errorf("%s/%s: filename %q is outside of the output directory", item.GetKind(), item.GetName(), buffer.String())
+	}
+
+	return filename, nil
+}
+
+// writeFile writes the given resources to the given file as a stream of yaml
+// documents, creating any parent directories.
+func writeFile(filename string, items []resources.Resource) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil { //nolint:mnd
+		return err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close() //nolint:errcheck
+
+	if err := YAML(file, items); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,178 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package printer
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/joshdk/krf/references"
+	"github.com/joshdk/krf/resources"
+)
+
+// Graph prints the references between each given resources.Resource as a
+// directed graph in Graphviz DOT format. Resources that are referenced, but
+// not present in the given corpus, are drawn with a dashed outline.
+func Graph(w io.Writer, corpus []resources.Resource, results []resources.Resource) error {
+	nodes, edges := referenceGraph(corpus, results)
+
+	fmt.Fprintln(w, "digraph resources {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+
+	for _, node := range nodes {
+		attributes := fmt.Sprintf("label=%q", node.label("\n"))
+		if node.missing {
+			attributes += ", style=dashed, color=gray, fontcolor=gray"
+		}
+
+		fmt.Fprintf(w, "  %q [%s];\n", node.id(), attributes)
+	}
+
+	for _, edge := range edges {
+		fmt.Fprintf(w, "  %q -> %q;\n", edge.from.id(), edge.to.id())
+	}
+
+	fmt.Fprintln(w, "}")
+
+	return nil
+}
+
+// Mermaid prints the references between each given resources.Resource as a
+// Mermaid flowchart. Resources that are referenced, but not present in the
+// given corpus, are drawn with a dashed outline.
+func Mermaid(w io.Writer, corpus []resources.Resource, results []resources.Resource) error {
+	nodes, edges := referenceGraph(corpus, results)
+
+	// Mermaid node ids are restricted to simple characters, so each node is
+	// instead assigned a sequential id.
+	ids := make(map[graphNode]string, len(nodes))
+
+	fmt.Fprintln(w, "flowchart LR")
+
+	for i, node := range nodes {
+		ids[node] = fmt.Sprintf("n%d", i)
+
+		label := strings.ReplaceAll(node.label("<br>"), `"`, "#quot;")
+		if node.missing {
+			fmt.Fprintf(w, "  %s[\"%s\"]:::missing\n", ids[node], label)
+		} else {
+			fmt.Fprintf(w, "  %s[\"%s\"]\n", ids[node], label)
+		}
+	}
+
+	for _, edge := range edges {
+		fmt.Fprintf(w, "  %s --> %s\n", ids[edge.from], ids[edge.to])
+	}
+
+	fmt.Fprintln(w, "  classDef missing stroke-dasharray: 5 5,color:#999")
+
+	return nil
+}
+
+// graphNode is a single resource in a reference graph.
+type graphNode struct {
+	namespace string
+	kind      string
+	name      string
+	missing   bool
+}
+
+// id returns a unique identifier for this node.
+func (n graphNode) id() string {
+	return fmt.Sprintf("%s/%s/%s", n.namespace, n.kind, n.name)
+}
+
+// label returns a human-readable label for this node, with the namespace (if
+// any) separated by the given line break.
+func (n graphNode) label(lineBreak string) string {
+	if n.namespace == "" {
+		return fmt.Sprintf("%s/%s", n.kind, n.name)
+	}
+
+	return fmt.Sprintf("%s/%s%s(%s)", n.kind, n.name, lineBreak, n.namespace)
+}
+
+// graphEdge is a single reference between two resources in a reference graph.
+type graphEdge struct {
+	from graphNode
+	to   graphNode
+}
+
+// referenceGraph builds a sorted list of nodes and edges from the references
+// between each given resources.Resource. Referenced resources that are not
+// present in the given corpus are marked as missing.
+func referenceGraph(corpus []resources.Resource, results []resources.Resource) ([]graphNode, []graphEdge) {
+	var (
+		nodes   = make(map[string]graphNode)
+		edges   = make(map[graphEdge]struct{})
+		present = make(map[string]bool, len(corpus))
+	)
+
+	for _, item := range corpus {
+		present[graphNode{namespace: item.GetNamespace(), kind: item.GetKind(), name: item.GetName()}.id()] = true
+	}
+
+	for _, item := range results {
+		node := graphNode{
+			namespace: item.GetNamespace(),
+			kind:      item.GetKind(),
+			name:      item.GetName(),
+		}
+
+		nodes[node.id()] = node
+	}
+
+	for _, item := range results {
+		from := nodes[graphNode{namespace: item.GetNamespace(), kind: item.GetKind(), name: item.GetName()}.id()]
+
+		references.All(item.Unstructured, func(kind, name string) {
+			to := graphNode{
+				namespace: references.Namespace(item.GetNamespace(), kind),
+				kind:      kind,
+				name:      name,
+			}
+
+			// Use the existing node if the referenced resource is one of the
+			// given resources, and otherwise add a node which is missing
+			// unless the referenced resource was merely filtered out.
+			if existing, found := nodes[to.id()]; found {
+				to = existing
+			} else {
+				to.missing = !present[to.id()]
+				nodes[to.id()] = to
+			}
+
+			edges[graphEdge{from: from, to: to}] = struct{}{}
+		})
+	}
+
+	nodeList := make([]graphNode, 0, len(nodes))
+	for _, node := range nodes {
+		nodeList = append(nodeList, node)
+	}
+
+	slices.SortFunc(nodeList, func(a, b graphNode) int {
+		return strings.Compare(a.id(), b.id())
+	})
+
+	edgeList := make([]graphEdge, 0, len(edges))
+	for edge := range edges {
+		edgeList = append(edgeList, edge)
+	}
+
+	slices.SortFunc(edgeList, func(a, b graphEdge) int {
+		return cmp.Or(
+			strings.Compare(a.from.id(), b.from.id()),
+			strings.Compare(a.to.id(), b.to.id()),
+		)
+	})
+
+	return nodeList, edgeList
+}
@@ -0,0 +1,66 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package printer_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/joshdk/krf/config"
+	"github.com/joshdk/krf/printer"
+	"github.com/joshdk/krf/resolver"
+	"github.com/joshdk/krf/resources"
+)
+
+func TestGraph(t *testing.T) {
+	cfg, err := config.Load("../config/files/configuration.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolver.Init(cfg.Resources)
+
+	deployment := resources.Resource{Unstructured: unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": "web", "namespace": "default"},
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+			"volumes": []any{
+				map[string]any{"name": "config", "configMap": map[string]any{"name": "settings"}},
+				map[string]any{"name": "secret", "secret": map[string]any{"secretName": "credentials"}},
+			},
+		}}},
+	}}}
+
+	configMap := resources.Resource{Unstructured: unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "settings", "namespace": "default"},
+	}}}
+
+	// The ConfigMap was decoded but filtered out, while the Secret is missing
+	// from the input entirely.
+	var buf bytes.Buffer
+	if err := printer.Graph(&buf, []resources.Resource{deployment, configMap}, []resources.Resource{deployment}); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+
+	for _, line := range []string{
+		`"default/ConfigMap/settings" [label="ConfigMap/settings\n(default)"];`,
+		`"default/Secret/credentials" [label="Secret/credentials\n(default)", style=dashed, color=gray, fontcolor=gray];`,
+		`"default/Deployment/web" -> "default/ConfigMap/settings";`,
+		`"default/Deployment/web" -> "default/Secret/credentials";`,
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected output to contain %s, got:\n%s", line, output)
+		}
+	}
+}
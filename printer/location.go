@@ -0,0 +1,46 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package printer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/joshdk/krf/resources"
+)
+
+// Location prints the file path and line of each given resources.Resource, in
+// the "path:line: message" format used by compilers and grep, so that editors
+// and CI systems can jump directly to each resource. If the given locate
+// function returns specific lines of a resource (like the line of a matching
+// field), then each of those lines are printed instead.
+func Location(w io.Writer, locate func(resources.Resource) []int, results []resources.Resource) error {
+	for _, item := range results {
+		path := item.GetFilename()
+		if path == "" {
+			path = "(standard input)"
+		}
+
+		message := fmt.Sprintf("%s/%s", item.GetKind(), item.GetName())
+
+		lines := locate(item)
+		if len(lines) == 0 && item.GetLine() != 0 {
+			lines = []int{item.GetLine()}
+		}
+
+		if len(lines) == 0 {
+			fmt.Fprintf(w, "%s: %s\n", path, message)
+
+			continue
+		}
+
+		for _, line := range lines {
+			fmt.Fprintf(w, "%s:%d: %s\n", path, line, message)
+		}
+	}
+
+	return nil
+}
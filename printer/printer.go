@@ -9,21 +9,70 @@
 package printer
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"golang.org/x/term"
 
+	"github.com/joshdk/krf/diff"
 	"github.com/joshdk/krf/resources"
 )
 
+// Options holds additional context that is needed by some printers.
+type Options struct {
+	// Corpus returns every decoded resources.Resource, regardless of whether
+	// it was matched. Only valid once all resources have been decoded.
+	Corpus func() []resources.Resource
+
+	// Baseline returns every resources.Resource decoded from the --diff
+	// file, which is used as the original counterparts of each resource.
+	Baseline func() []resources.Resource
+
+	// Diff determines how resources are paired with, and then compared
+	// against, their counterparts in the baseline.
+	Diff diff.Options
+
+	// Locate returns the specific lines of a resources.Resource that caused
+	// it to be matched.
+	Locate func(resources.Resource) []int
+}
+
 // ByName returns a printer function from the given name. If no name is given
 // and the program output is being redirected or piped to a consumer process,
 // hen default to the YAML printer. Additionally, if no name is given and the
 // program output is being sent directly to the terminal, then instead default
-// to the Table printer.
-func ByName(name string) (func(io.Writer, []resources.Resource) error, error) {
+// to the Table printer. Some printers additionally take an argument, given
+// after the name like "jsonpath={.items[*].metadata.name}".
+func ByName(name string, options Options) (func(io.Writer, []resources.Resource) error, error) {
+	name, arg, hasArg := strings.Cut(name, "=")
+
+	switch name {
+	case "custom-columns":
+		return CustomColumns(arg)
+
+	case "custom-columns-file":
+		return withFile(arg, customColumnsFile)
+
+	case "go-template":
+		return GoTemplate(arg)
+
+	case "go-template-file":
+		return withFile(arg, GoTemplate)
+
+	case "jsonpath":
+		return JSONPath(arg)
+
+	case "jsonpath-file":
+		return withFile(arg, JSONPath)
+	}
+
+	if hasArg {
+		return nil, fmt.Errorf("printer %s does not take an argument", name)
+	}
+
 	switch name {
 	case "":
 		// Is program output being redirected or piped to a consumer process?
@@ -34,9 +83,37 @@ func ByName(name string) (func(io.Writer, []resources.Resource) error, error) {
 		// Default for when output is directly to a terminal.
 		return Table, nil
 
+	case "blame":
+		return Blame, nil
+
+	case "dangling":
+		return func(w io.Writer, results []resources.Resource) error {
+			return Dangling(w, options.Corpus(), results)
+		}, nil
+
+	case "diff":
+		return func(w io.Writer, results []resources.Resource) error {
+			return Diff(w, options.Diff, options.Baseline(), results)
+		}, nil
+
+	case "graph":
+		return func(w io.Writer, results []resources.Resource) error {
+			return Graph(w, options.Corpus(), results)
+		}, nil
+
 	case "json":
 		return JSON, nil
 
+	case "location":
+		return func(w io.Writer, results []resources.Resource) error {
+			return Location(w, options.Locate, results)
+		}, nil
+
+	case "mermaid":
+		return func(w io.Writer, results []resources.Resource) error {
+			return Mermaid(w, options.Corpus(), results)
+		}, nil
+
 	case "name":
 		return Name, nil
 
@@ -46,6 +123,11 @@ func ByName(name string) (func(io.Writer, []resources.Resource) error, error) {
 	case "references":
 		return References, nil
 
+	case "selections":
+		return func(w io.Writer, results []resources.Resource) error {
+			return Selections(w, options.Corpus(), results)
+		}, nil
+
 	case "selector":
 		return Selector, nil
 
@@ -59,3 +141,18 @@ func ByName(name string) (func(io.Writer, []resources.Resource) error, error) {
 		return nil, fmt.Errorf("unknown printer name: %s", name)
 	}
 }
+
+// withFile reads the given file, and passes the contents to the given printer
+// constructor.
+func withFile(filename string, fn func(string) (func(io.Writer, []resources.Resource) error, error)) (func(io.Writer, []resources.Resource) error, error) {
+	if filename == "" {
+		return nil, errors.New("no printer file given")
+	}
+
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return fn(string(contents))
+}
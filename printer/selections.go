@@ -0,0 +1,90 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package printer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rodaine/table"
+
+	"github.com/joshdk/krf/references"
+	"github.com/joshdk/krf/resources"
+)
+
+// Selections prints the selector relationships between each given
+// resources.Resource and the resources in the given corpus, as rows in a
+// formatted table. Resources with a selector (like a Service) are listed with
+// each workload that they select, and workloads are listed with each resource
+// that selects them. Selectors that select nothing, and workloads that are not
+// selected by any Service, are also called out.
+func Selections(w io.Writer, corpus []resources.Resource, results []resources.Resource) error {
+	headers := []any{"Namespace", "Resource", "Relation", "Target"}
+
+	// Check if any of the resources were decoded from a file opposed to from
+	// e.g. stdin.
+	for _, item := range results {
+		if item.GetFilename() != "" {
+			headers = append(headers, "Path")
+
+			break
+		}
+	}
+
+	tbl := table.New(headers...)
+	tbl.WithHeaderSeparatorRow('─')
+	tbl.WithWriter(w)
+
+	addRow := func(item resources.Resource, relation string, target string) {
+		tbl.AddRow(
+			item.GetNamespace(),
+			fmt.Sprintf("%s/%s", item.GetKind(), item.GetName()),
+			relation,
+			target,
+			item.GetFilename(),
+		)
+	}
+
+	for _, item := range results {
+		if references.IsSelector(item.Unstructured) {
+			var selected bool
+
+			for _, workload := range corpus {
+				if references.Selects(item.Unstructured, workload.Unstructured) {
+					selected = true
+
+					addRow(item, "selects", fmt.Sprintf("%s/%s", workload.GetKind(), workload.GetName()))
+				}
+			}
+
+			if !selected {
+				addRow(item, "selects nothing", "")
+			}
+		}
+
+		if references.IsWorkload(item.Unstructured) {
+			var serviced bool
+
+			for _, selector := range corpus {
+				if references.Selects(selector.Unstructured, item.Unstructured) {
+					if selector.GetKind() == "Service" {
+						serviced = true
+					}
+
+					addRow(item, "selected by", fmt.Sprintf("%s/%s", selector.GetKind(), selector.GetName()))
+				}
+			}
+
+			if !serviced {
+				addRow(item, "no service", "")
+			}
+		}
+	}
+
+	tbl.Print()
+
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package printer
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+	"sigs.k8s.io/yaml"
+
+	"github.com/joshdk/krf/resources"
+)
+
+// Stream renders a single resources.Resource at a time, so that each can be
+// printed as soon as it is matched.
+type Stream struct {
+	// Render renders a single resources.Resource. Safe to call concurrently.
+	Render func(resources.Resource) ([]byte, error)
+
+	// Separator is printed between each rendered resources.Resource.
+	Separator string
+}
+
+// Streaming returns a Stream for the named printer, if that printer supports
+// it (only the yaml, json, and name printers do). Otherwise, nil is returned.
+// Unlike the Name printer, names are neither sorted nor deduplicated.
+func Streaming(name string) *Stream {
+	switch name {
+	case "":
+		// Default to the Table printer when output is directly to a terminal.
+		if term.IsTerminal(int(os.Stdout.Fd())) {
+			return nil
+		}
+
+		return Streaming("yaml")
+
+	case "json":
+		return &Stream{Render: func(item resources.Resource) ([]byte, error) {
+			return item.MarshalJSON()
+		}}
+
+	case "name":
+		return &Stream{Render: func(item resources.Resource) ([]byte, error) {
+			return fmt.Appendf(nil, "%s/%s\n", item.GetKind(), item.GetName()), nil
+		}}
+
+	case "yaml":
+		return &Stream{
+			Render: func(item resources.Resource) ([]byte, error) {
+				return yaml.Marshal(item.Object)
+			},
+			Separator: "---\n",
+		}
+
+	default:
+		return nil
+	}
+}
@@ -15,16 +15,25 @@ import (
 
 // Table prints each given resources.Resource as a row in a formatted table.
 func Table(w io.Writer, items []resources.Resource) error {
-	headers := []any{"Namespace", "API Version", "Kind", "Name"}
+	var hasSource, hasPath bool
 
-	// Check if any of the resources were decoded from a file opposed to from
-	// e.g. stdin.
+	// Check if any of the resources were decoded from a labelled source, or
+	// from a file opposed to from e.g. stdin.
 	for _, item := range items {
-		if item.GetFilename() != "" {
-			headers = append(headers, "Path")
+		hasSource = hasSource || item.GetSource() != ""
+		hasPath = hasPath || item.GetFilename() != ""
+	}
 
-			break
-		}
+	var headers []any
+
+	if hasSource {
+		headers = append(headers, "Source")
+	}
+
+	headers = append(headers, "Namespace", "API Version", "Kind", "Name")
+
+	if hasPath {
+		headers = append(headers, "Path")
 	}
 
 	tbl := table.New(headers...)
@@ -32,7 +41,19 @@ func Table(w io.Writer, items []resources.Resource) error {
 	tbl.WithWriter(w)
 
 	for _, item := range items {
-		tbl.AddRow(item.GetNamespace(), item.GetAPIVersion(), item.GetKind(), item.GetName(), item.GetFilename())
+		var row []any
+
+		if hasSource {
+			row = append(row, item.GetSource())
+		}
+
+		row = append(row, item.GetNamespace(), item.GetAPIVersion(), item.GetKind(), item.GetName())
+
+		if hasPath {
+			row = append(row, item.GetFilename())
+		}
+
+		tbl.AddRow(row...)
 	}
 
 	tbl.Print()
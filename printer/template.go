@@ -0,0 +1,79 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package printer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/joshdk/krf/resources"
+)
+
+// GoTemplate returns a printer function which executes the given Go template
+// in the same way as kubectl. The template is executed once, against a v1.List
+// containing every given resources.Resource (like "{{range .items}}...").
+func GoTemplate(text string) (func(io.Writer, []resources.Resource) error, error) {
+	if text == "" {
+		return nil, errors.New("go-template format specified but no template given")
+	}
+
+	tmpl, err := template.New("output").Funcs(template.FuncMap{
+		"base64decode": func(value string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return "", fmt.Errorf("base64decode: %w", err)
+			}
+
+			return string(decoded), nil
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing go-template: %w", err)
+	}
+
+	return func(w io.Writer, items []resources.Resource) error {
+		return tmpl.Execute(w, newList(items))
+	}, nil
+}
+
+// JSONPath returns a printer function which executes the given jsonpath
+// template in the same way as kubectl. The template is executed once, against
+// a v1.List containing every given resources.Resource (like
+// "{.items[*].metadata.name}").
+func JSONPath(text string) (func(io.Writer, []resources.Resource) error, error) {
+	if text == "" {
+		return nil, errors.New("jsonpath format specified but no jsonpath template given")
+	}
+
+	path := jsonpath.New("output").AllowMissingKeys(true)
+
+	if err := path.Parse(text); err != nil {
+		return nil, fmt.Errorf("parsing jsonpath: %w", err)
+	}
+
+	return func(w io.Writer, items []resources.Resource) error {
+		return path.Execute(w, newList(items))
+	}, nil
+}
+
+// newList returns a v1.List containing the given resources.Resource objects.
+func newList(items []resources.Resource) map[string]any {
+	objects := make([]any, len(items))
+	for index, item := range items {
+		objects[index] = item.Object
+	}
+
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      objects,
+	}
+}
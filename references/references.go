@@ -51,3 +51,60 @@ func All(uu unstructured.Unstructured, callback func(kind, name string)) {
 		return false
 	})
 }
+
+// Namespace returns the namespace of a resource of the given kind, when
+// referenced by a resource in the given namespace. References to namespaced
+// resources are always to resources in the same namespace, while references
+// to cluster-scoped resources (as known by the resolver) have no namespace.
+func Namespace(namespace string, kind string) string {
+	if resource, found := resolver.LookupKind(kind); found && !resource.Namespaced {
+		return ""
+	}
+
+	return namespace
+}
+
+// Index is a collection of resources, used to determine if references
+// between resources can be resolved.
+type Index map[indexKey]struct{}
+
+// indexKey uniquely identifies a single resource within an Index.
+type indexKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// NewIndex returns an Index containing each given unstructured.Unstructured.
+func NewIndex(items []unstructured.Unstructured) Index {
+	index := make(Index, len(items))
+
+	for _, item := range items {
+		index.Add(item.GetKind(), item.GetNamespace(), item.GetName())
+	}
+
+	return index
+}
+
+// Add adds a resource with the given kind, namespace, and name to the index.
+func (i Index) Add(kind string, namespace string, name string) {
+	i[indexKey{kind: kind, namespace: namespace, name: name}] = struct{}{}
+}
+
+// Contains reports if a resource with the given kind, namespace, and name is
+// present in the index.
+func (i Index) Contains(kind string, namespace string, name string) bool {
+	_, found := i[indexKey{kind: kind, namespace: namespace, name: name}]
+
+	return found
+}
+
+// Dangling iterates over all named resource references in the given
+// unstructured.Unstructured that do not resolve to a resource in the index.
+func (i Index) Dangling(uu unstructured.Unstructured, callback func(kind, name string)) {
+	All(uu, func(kind, name string) {
+		if !i.Contains(kind, Namespace(uu.GetNamespace(), kind), name) {
+			callback(kind, name)
+		}
+	})
+}
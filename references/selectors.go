@@ -0,0 +1,130 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package references
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// IsWorkload reports if the given unstructured.Unstructured is a workload
+// which runs pods, and can therefore be selected by pod labels. Deployments,
+// StatefulSets, DaemonSets, and bare Pods are supported.
+func IsWorkload(uu unstructured.Unstructured) bool {
+	_, found := podLabels(uu)
+
+	return found
+}
+
+// IsSelector reports if the given unstructured.Unstructured selects workloads.
+// Services, PodDisruptionBudgets, and NetworkPolicies (which select workloads
+// by pod labels) as well as HorizontalPodAutoscalers (which select a workload
+// by name) are supported.
+func IsSelector(uu unstructured.Unstructured) bool {
+	if uu.GetKind() == "HorizontalPodAutoscaler" {
+		_, found, _ := unstructured.NestedString(uu.Object, "spec", "scaleTargetRef", "name")
+
+		return found
+	}
+
+	_, found := podSelector(uu)
+
+	return found
+}
+
+// Selects reports if the given selector unstructured.Unstructured selects the
+// given workload unstructured.Unstructured. Both must be in the same
+// namespace.
+func Selects(selector unstructured.Unstructured, workload unstructured.Unstructured) bool {
+	if selector.GetNamespace() != workload.GetNamespace() {
+		return false
+	}
+
+	// HorizontalPodAutoscalers select a single workload by kind and name.
+	if selector.GetKind() == "HorizontalPodAutoscaler" {
+		kind, _, _ := unstructured.NestedString(selector.Object, "spec", "scaleTargetRef", "kind")
+		name, _, _ := unstructured.NestedString(selector.Object, "spec", "scaleTargetRef", "name")
+
+		return kind == workload.GetKind() && name == workload.GetName()
+	}
+
+	sel, found := podSelector(selector)
+	if !found {
+		return false
+	}
+
+	podLabels, found := podLabels(workload)
+	if !found {
+		return false
+	}
+
+	return sel.Matches(labels.Set(podLabels))
+}
+
+// podSelector returns the pod label selector for the given
+// unstructured.Unstructured, and whether it has one at all.
+func podSelector(uu unstructured.Unstructured) (labels.Selector, bool) {
+	switch uu.GetKind() {
+	case "Service":
+		// Services use a simple map of labels. A Service without a selector
+		// (like an ExternalName Service) selects nothing.
+		selector, found, _ := unstructured.NestedStringMap(uu.Object, "spec", "selector")
+		if !found || len(selector) == 0 {
+			return nil, false
+		}
+
+		return labels.SelectorFromSet(selector), true
+
+	case "PodDisruptionBudget":
+		return labelSelector(uu, "spec", "selector")
+
+	case "NetworkPolicy":
+		return labelSelector(uu, "spec", "podSelector")
+
+	default:
+		return nil, false
+	}
+}
+
+// labelSelector converts the metav1.LabelSelector located at the given path
+// into a labels.Selector.
+func labelSelector(uu unstructured.Unstructured, fields ...string) (labels.Selector, bool) {
+	raw, found, _ := unstructured.NestedMap(uu.Object, fields...)
+	if !found {
+		return nil, false
+	}
+
+	var ls metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &ls); err != nil {
+		return nil, false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&ls)
+	if err != nil {
+		return nil, false
+	}
+
+	return selector, true
+}
+
+// podLabels returns the labels of the pods run by the given
+// unstructured.Unstructured, and whether it runs pods at all.
+func podLabels(uu unstructured.Unstructured) (map[string]string, bool) {
+	switch uu.GetKind() {
+	case "Deployment", "StatefulSet", "DaemonSet":
+		podLabels, _, _ := unstructured.NestedStringMap(uu.Object, "spec", "template", "metadata", "labels")
+
+		return podLabels, true
+
+	case "Pod":
+		return uu.GetLabels(), true
+
+	default:
+		return nil, false
+	}
+}
@@ -70,3 +70,18 @@ func LookupKind(kind string) (Resource, bool) {
 
 	return Resource{}, false
 }
+
+// IsReferenced reports if the given kind can be referenced by any known
+// Resource metadata definition. For example, this would be true for a
+// ConfigMap since it can be referenced by a Pod.
+func IsReferenced(kind string) bool {
+	for _, resource := range resources {
+		for _, reference := range resource.References {
+			if kind == reference.Kind {
+				return true
+			}
+		}
+	}
+
+	return false
+}
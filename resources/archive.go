@@ -0,0 +1,282 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ArchiveSeparator separates the name of an archive from the path of a file
+// within that archive, like "bundle.tgz!/manifests/deployment.yaml".
+const ArchiveSeparator = "!/"
+
+const (
+	// defaultArchiveBytes is the total number of bytes extracted from each
+	// archive, unless configured otherwise using WithArchiveLimits.
+	defaultArchiveBytes = 1 << 30
+
+	// defaultArchiveEntries is the number of files extracted from each
+	// archive, unless configured otherwise using WithArchiveLimits.
+	defaultArchiveEntries = 100_000
+)
+
+// decompress returns a reader which decompresses the given stream, if it is
+// compressed using gzip, zstd, or bzip2, along with true. Otherwise, the
+// stream is returned as is. Compression is detected by peeking at the first
+// few bytes of the stream.
+func decompress(reader *bufio.Reader) (io.ReadCloser, bool, error) {
+	magic, _ := reader.Peek(4) //nolint:mnd
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		stream, err := gzip.NewReader(reader)
+
+		return stream, true, err
+
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		stream, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return stream.IOReadCloser(), true, nil
+
+	case len(magic) == 4 && bytes.HasPrefix(magic, []byte("BZh")) && '1' <= magic[3] && magic[3] <= '9':
+		return io.NopCloser(bzip2.NewReader(reader)), true, nil
+
+	default:
+		return io.NopCloser(reader), false, nil
+	}
+}
+
+// archiveFormat returns the format ("tar" or "zip") of the given stream, by
+// peeking at its first few hundred bytes. Nothing is returned if the stream
+// is not an archive.
+func archiveFormat(reader *bufio.Reader) string {
+	header, _ := reader.Peek(262) //nolint:mnd
+
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return "zip"
+	case len(header) == 262 && string(header[257:262]) == "ustar":
+		return "tar"
+	default:
+		return ""
+	}
+}
+
+// decodeArchive decodes Kubernetes resources from the given tar or zip
+// archive, which is extracted and then walked in the same way as a directory.
+// The filename of each resource is set to the name of the archive, followed
+// by the path of the file within that archive, like
+// "bundle.tgz!/manifests/deployment.yaml".
+func decodeArchive(reader io.Reader, format, name string, handler ResourceFunc, opts []Option) error {
+	directory, err := os.MkdirTemp("", "krf-archive-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(directory) //nolint:errcheck
+
+	limits := newOptions(opts).archive
+	if limits.maxBytes == 0 {
+		limits.maxBytes = defaultArchiveBytes
+	}
+
+	if limits.maxEntries == 0 {
+		limits.maxEntries = defaultArchiveEntries
+	}
+
+	extract := &extractor{directory: directory, limits: limits}
+
+	switch format {
+	case "tar":
+		err = extract.extractTar(reader)
+	case "zip":
+		err = extract.extractZip(reader)
+	}
+
+	if err != nil {
+		return fmt.Errorf("extracting %s archive: %w", format, err)
+	}
+
+	// Resources from within an archive cannot be edited in place.
+	packedHandler := handler
+	handler = func(item Resource) {
+		item.packed = true
+		packedHandler(item)
+	}
+
+	handler, opts = renameFiles(handler, opts, func(filename string) string {
+		relative, err := filepath.Rel(directory, filename)
+		if err != nil {
+			return filename
+		}
+
+		return name + ArchiveSeparator + filepath.ToSlash(relative)
+	})
+
+	return decodeDirectory(directory, handler, opts)
+}
+
+// extractor extracts files from an archive into a directory, failing once
+// either of its limits are exceeded.
+type extractor struct {
+	directory string
+	limits    archiveLimits
+
+	// bytes is the total number of bytes extracted so far.
+	bytes int64
+
+	// entries is the number of files extracted so far.
+	entries int
+}
+
+// extractTar extracts every regular file from the given tar archive.
+func (e *extractor) extractTar(reader io.Reader) error {
+	archive := tar.NewReader(reader)
+
+	for {
+		header, err := archive.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		// Ignore directories (which are created as needed), along with any
+		// symlinks or other special files.
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := e.write(header.Name, archive); err != nil {
+			return err
+		}
+	}
+}
+
+// extractZip extracts every regular file from the given zip archive. The
+// archive is first buffered into a temporary file, as zip archives cannot be
+// read as a stream.
+func (e *extractor) extractZip(reader io.Reader) error {
+	buffer, err := os.CreateTemp("", "krf-archive-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(buffer.Name()) //nolint:errcheck
+	defer buffer.Close()           //nolint:errcheck
+
+	// The archive itself is limited to the same size as its contents.
+	size, err := io.Copy(buffer, io.LimitReader(reader, e.limits.maxBytes+1))
+	if err != nil {
+		return err
+	}
+
+	if size > e.limits.maxBytes {
+		return fmt.Errorf("archive is larger than the limit of %d bytes", e.limits.maxBytes)
+	}
+
+	archive, err := zip.NewReader(buffer, size)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range archive.File {
+		// Ignore directories (which are created as needed), along with any
+		// symlinks or other special files.
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+
+		if err := e.extractZipFile(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractZipFile extracts the given zip archive entry.
+func (e *extractor) extractZipFile(entry *zip.File) error {
+	contents, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer contents.Close() //nolint:errcheck
+
+	return e.write(entry.Name, contents)
+}
+
+// write writes the given contents to the given (slash separated) path within
+// the extraction directory. The path must remain within the directory.
+func (e *extractor) write(name string, contents io.Reader) error {
+	relative := filepath.FromSlash(path.Clean(name))
+	if !filepath.IsLocal(relative) {
+		return fmt.Errorf("entry %q is outside of the archive", name)
+	}
+
+	if e.entries++; e.entries > e.limits.maxEntries {
+		return fmt.Errorf("archive contains more than the limit of %d files", e.limits.maxEntries)
+	}
+
+	filename := filepath.Join(e.directory, relative)
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil { //nolint:mnd
+		return err
+	}
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) //nolint:mnd
+	if err != nil {
+		return err
+	}
+	defer file.Close() //nolint:errcheck
+
+	// At most one byte more than the remaining limit is read, which is enough
+	// to tell that the limit was exceeded.
+	written, err := io.Copy(file, io.LimitReader(contents, e.limits.maxBytes-e.bytes+1))
+	if err != nil {
+		return err
+	}
+
+	if e.bytes += written; e.bytes > e.limits.maxBytes {
+		return fmt.Errorf("archive contents are larger than the limit of %d bytes", e.limits.maxBytes)
+	}
+
+	return file.Close()
+}
+
+// readFile reads the given file, decompressing its contents if the file is
+// compressed.
+func readFile(filename string) ([]byte, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() //nolint:errcheck
+
+	stream, _, err := decompress(bufio.NewReader(file))
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close() //nolint:errcheck
+
+	return io.ReadAll(stream)
+}
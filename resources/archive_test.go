@@ -0,0 +1,255 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/joshdk/krf/resources"
+)
+
+func TestDecodeArchive(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	directory := t.TempDir()
+
+	files := map[string]string{
+		"manifests/a.yaml":      "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n",
+		"manifests/sub/b.yaml":  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n",
+		"manifests/ignored.txt": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ignored\n",
+	}
+
+	names := []string{"manifests/a.yaml", "manifests/ignored.txt", "manifests/sub/b.yaml"}
+
+	writeFile := func(name string, contents []byte) string {
+		filename := filepath.Join(directory, name)
+		if err := os.WriteFile(filename, contents, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		return filename
+	}
+
+	var tarball bytes.Buffer
+
+	tarWriter := tar.NewWriter(&tarball)
+	for _, name := range names {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(files[name]))}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tarWriter.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var zipball bytes.Buffer
+
+	zipWriter := zip.NewWriter(&zipball)
+	for _, name := range names {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := writer.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	gzipped := func(data []byte) []byte {
+		var buffer bytes.Buffer
+
+		writer := gzip.NewWriter(&buffer)
+		if _, err := writer.Write(data); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		return buffer.Bytes()
+	}
+
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		source   string
+		stdin    []byte
+		expected []string
+	}{
+		"gzip": {
+			source:   writeFile("a.yaml.gz", gzipped([]byte(files["manifests/a.yaml"]))),
+			expected: []string{"a.yaml.gz a"},
+		},
+		"zstd": {
+			source:   writeFile("a.yaml.zst", encoder.EncodeAll([]byte(files["manifests/a.yaml"]), nil)),
+			expected: []string{"a.yaml.zst a"},
+		},
+		"bzip2": {
+			source:   "testdata/multiple.yaml.bz2",
+			expected: []string{"testdata/multiple.yaml.bz2 nginx-deployment", "testdata/multiple.yaml.bz2 my-service", "testdata/multiple.yaml.bz2 myconfigmap"},
+		},
+		"tar": {
+			source:   writeFile("bundle.tar", tarball.Bytes()),
+			expected: []string{"bundle.tar!/manifests/a.yaml a", "bundle.tar!/manifests/sub/b.yaml b"},
+		},
+		"tar gzip": {
+			source:   writeFile("bundle.tgz", gzipped(tarball.Bytes())),
+			expected: []string{"bundle.tgz!/manifests/a.yaml a", "bundle.tgz!/manifests/sub/b.yaml b"},
+		},
+		"zip": {
+			source:   writeFile("bundle.zip", zipball.Bytes()),
+			expected: []string{"bundle.zip!/manifests/a.yaml a", "bundle.zip!/manifests/sub/b.yaml b"},
+		},
+		"stdin gzip": {
+			stdin:    gzipped([]byte(files["manifests/a.yaml"])),
+			expected: []string{" a"},
+		},
+		"stdin tar gzip": {
+			stdin:    gzipped(tarball.Bytes()),
+			expected: []string{"(stdin)!/manifests/a.yaml a", "(stdin)!/manifests/sub/b.yaml b"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var source any = test.source
+			if test.stdin != nil {
+				source = io.Reader(bytes.NewReader(test.stdin))
+			}
+
+			var results []string
+
+			err := resources.Decode(source, func(item resources.Resource) {
+				filename := strings.TrimPrefix(item.GetFilename(), directory+string(filepath.Separator))
+				results = append(results, filename+" "+item.GetName())
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.expected, results); diff != "" {
+				t.Errorf("unexpected resources (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeArchiveOutside(t *testing.T) {
+	t.Parallel()
+
+	var tarball bytes.Buffer
+
+	contents := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n"
+
+	writer := tar.NewWriter(&tarball)
+	if err := writer.WriteHeader(&tar.Header{Name: "../a.yaml", Mode: 0o600, Size: int64(len(contents))}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := writer.Write([]byte(contents)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := resources.Reader(&tarball, func(resources.Resource) {
+		t.Error("expected no resources")
+	})
+	if err == nil || !strings.Contains(err.Error(), "outside of the archive") {
+		t.Fatalf("expected an outside of the archive error, got %v", err)
+	}
+}
+
+func TestDecodeArchiveLimits(t *testing.T) {
+	t.Parallel()
+
+	var tarball bytes.Buffer
+
+	writer := tar.NewWriter(&tarball)
+	for _, name := range []string{"a", "b", "c"} {
+		contents := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n"
+
+		if err := writer.WriteHeader(&tar.Header{Name: name + ".yaml", Mode: 0o600, Size: int64(len(contents))}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := writer.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		maxBytes   int64
+		maxEntries int
+		expected   string
+	}{
+		"defaults": {},
+		"bytes": {
+			maxBytes: 100,
+			expected: "extracting tar archive: archive contents are larger than the limit of 100 bytes",
+		},
+		"entries": {
+			maxEntries: 2,
+			expected:   "extracting tar archive: archive contains more than the limit of 2 files",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var count int
+
+			err := resources.Reader(bytes.NewReader(tarball.Bytes()), func(resources.Resource) {
+				count++
+			}, resources.WithArchiveLimits(test.maxBytes, test.maxEntries))
+
+			switch {
+			case test.expected == "" && err != nil:
+				t.Fatal(err)
+			case test.expected == "" && count != 3:
+				t.Fatalf("expected 3 resources, got %d", count)
+			case test.expected != "" && (err == nil || err.Error() != test.expected):
+				t.Fatalf("expected error %q, got %v", test.expected, err)
+			case test.expected != "" && count != 0:
+				t.Fatalf("expected no resources, got %d", count)
+			}
+		})
+	}
+}
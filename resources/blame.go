@@ -0,0 +1,199 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// BlameLine describes the git commit that last modified a single line.
+type BlameLine struct {
+	// Author is the name of the commit author.
+	Author string
+
+	// Email is the email address of the commit author.
+	Email string
+
+	// Date is when the commit was authored.
+	Date time.Time
+
+	// Commit is the commit hash, which is empty for lines that have not yet
+	// been committed.
+	Commit string
+}
+
+// notCommitted is used for lines that have not yet been committed, and
+// matches the author used by git blame.
+var notCommitted = BlameLine{Author: "Not Committed Yet", Email: "not.committed.yet"}
+
+// blameCache holds the blame results for each file, as resources commonly
+// share the same file.
+var blameCache = struct { //nolint:gochecknoglobals
+	sync.Mutex
+	files map[string]*blameEntry
+}{files: make(map[string]*blameEntry)}
+
+// blameEntry holds the blame results for a single file, which are computed
+// once, without blocking the blaming of any other files.
+type blameEntry struct {
+	once  sync.Once
+	lines []BlameLine
+	err   error
+}
+
+// Blame returns the git commit that last modified each of the lines occupied
+// by the given resource. Nothing is returned for resources that have no known
+// lines, like those that were read from stdin or rendered from a Helm chart.
+//
+// Behavior notes:
+// - Files are blamed as of the HEAD commit, and lines that were added or
+// changed since the HEAD commit are reported as not committed yet.
+// - Resources decoded from a git revision (like "main:./manifests") are blamed
+// as of that revision.
+func Blame(item Resource) ([]BlameLine, error) {
+	if item.filename == "" || item.line == 0 || item.packed {
+		return nil, nil
+	}
+
+	blameCache.Lock()
+
+	entry, found := blameCache.files[item.filename]
+	if !found {
+		entry = &blameEntry{}
+		blameCache.files[item.filename] = entry
+	}
+
+	blameCache.Unlock()
+
+	entry.once.Do(func() {
+		entry.lines, entry.err = blameFile(item.filename)
+	})
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+
+	if item.endLine > len(entry.lines) {
+		return nil, nil
+	}
+
+	return entry.lines[item.line-1 : item.endLine], nil
+}
+
+// blameFile returns the git commit that last modified each line of the given
+// file.
+func blameFile(filename string) ([]BlameLine, error) {
+	revision, relPath := "HEAD", ""
+
+	contents, err := os.ReadFile(filename)
+
+	switch {
+	case err == nil:
+	case os.IsNotExist(err) && strings.Contains(filename, ":"):
+		// The file might instead refer to a file at a git revision, which is
+		// named relative to the root of the repository.
+		revision, relPath = SplitRevision(filename)
+		contents = nil
+	default:
+		return nil, err
+	}
+
+	// Open the repository containing the file.
+	directory := "."
+	if contents != nil {
+		directory = filepath.Dir(filename)
+	}
+
+	repository, err := git.PlainOpenWithOptions(directory, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if contents != nil {
+		root, err := repositoryRoot(repository)
+		if err != nil {
+			return nil, err
+		}
+
+		absPath, err := filepath.Abs(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		if relPath, err = repositoryPath(root, absPath); err != nil {
+			return nil, err
+		}
+	}
+
+	hash, err := repository.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := repository.CommitObject(*hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var committed []*git.Line
+
+	if result, err := git.Blame(commit, relPath); err == nil {
+		committed = result.Lines
+	} else if contents == nil {
+		return nil, err
+	}
+
+	if contents == nil {
+		lines := make([]BlameLine, len(committed))
+		for i, line := range committed {
+			lines[i] = newBlameLine(line)
+		}
+
+		return lines, nil
+	}
+
+	// Align the lines in the current file with the committed lines, as the
+	// file could have been modified in the worktree. Lines that were added or
+	// changed have not been committed.
+	current := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+	lines := make([]BlameLine, len(current))
+
+	for i := range lines {
+		lines[i] = notCommitted
+		lines[i].Date = time.Now()
+	}
+
+	texts := make([]string, len(committed))
+	for i, line := range committed {
+		texts[i] = line.Text
+	}
+
+	for _, block := range difflib.NewMatcherWithJunk(texts, current, false, nil).GetMatchingBlocks() {
+		for offset := range block.Size {
+			lines[block.B+offset] = newBlameLine(committed[block.A+offset])
+		}
+	}
+
+	return lines, nil
+}
+
+// newBlameLine converts the given git.Line into a BlameLine.
+func newBlameLine(line *git.Line) BlameLine {
+	return BlameLine{
+		Author: line.AuthorName,
+		Email:  line.Author,
+		Date:   line.Date,
+		Commit: line.Hash.String(),
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+
+	"github.com/joshdk/krf/resources"
+)
+
+func TestBlame(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	directory := t.TempDir()
+	filename := filepath.Join(directory, "resources.yaml")
+
+	repository, err := git.PlainInit(directory, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit := func(author string, when time.Time, contents string) {
+		if err := os.WriteFile(filename, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := worktree.Add("resources.yaml"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := worktree.Commit("test", &git.CommitOptions{
+			Author: &object.Signature{Name: author, Email: author + "@example.com", When: when},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	document := func(name, value string) string {
+		return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\ndata:\n  value: " + value + "\n"
+	}
+
+	commit("alice", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		document("first", "a")+"---\n"+document("second", "a")+"---\n"+document("third", "a"))
+
+	commit("bob", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		document("first", "a")+"---\n"+document("second", "b")+"---\n"+document("third", "a"))
+
+	// Add a line to the first resource, and modify the last resource, without
+	// committing. The lines that follow the added line are still blamed on
+	// their original commits.
+	if err := os.WriteFile(filename, []byte(
+		document("first", "a")+"  extra: a\n---\n"+document("second", "b")+"---\n"+document("third", "c"),
+	), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"first":  {"alice", "alice", "alice", "alice", "alice", "alice", "Not Committed Yet"},
+		"second": {"alice", "alice", "alice", "alice", "alice", "bob"},
+		"third":  {"alice", "alice", "alice", "alice", "alice", "Not Committed Yet"},
+	}
+
+	err = resources.Decode(filename, func(item resources.Resource) {
+		lines, err := resources.Blame(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var authors []string
+		for _, line := range lines {
+			authors = append(authors, line.Author)
+		}
+
+		if !slices.Equal(expected[item.GetName()], authors) {
+			t.Errorf("expected authors %v for %s, got %v", expected[item.GetName()], item.GetName(), authors)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -15,19 +15,39 @@
 //
 // - A stream of yaml/json documents.
 //   - Produced by running "kustomize build".
+//
+// - A directory containing a Helm chart.
+//   - Rendered offline using the Helm template engine.
+//
+// - A directory containing a kustomization.
+//   - Built in-process using the kustomize API.
+//
+// - A file or directory at a git revision.
+//   - Read directly from the git object database.
+//
+// - A file or stream compressed using gzip, zstd, or bzip2.
+//   - Produced by "kubectl get -o yaml | gzip" for example.
+//
+// - A tar or zip archive of files.
+//   - Extracted and walked in the same way as a directory.
 package resources
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"golang.org/x/term"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/joshdk/krf/parallel"
 )
 
 // Resource represents a single Kubernetes resource. It holds the original
@@ -41,6 +61,31 @@ type Resource struct {
 	// only set if the resource was decoded from a file (opposed to being
 	// decoded from an io.Reader).
 	filename string
+
+	// patches are the kustomize patch files that were applied to the resource.
+	// This value is only set if the resource was built from a kustomization.
+	patches []string
+
+	// removed indicates that the resource only exists in a diff baseline, and
+	// is absent from the resources being compared against it.
+	removed bool
+
+	// source is the label of the source from which the resource was decoded.
+	// This value is only set if a label was given using WithSource.
+	source string
+
+	// line and endLine are the (1-indexed) range of lines that the resource
+	// occupied in the original file or stream, and document is the (0-indexed)
+	// position of the yaml/json document within that file or stream. These
+	// values are only set if the resource was decoded directly from a file or
+	// stream (opposed to being rendered from a Helm chart).
+	line     int
+	endLine  int
+	document int
+
+	// packed indicates that the resource was decoded from a compressed file,
+	// or from a file within an archive, and so cannot be edited in place.
+	packed bool
 }
 
 // GetFilename returns the filename from which this resource was originally
@@ -50,6 +95,51 @@ func (i Resource) GetFilename() string {
 	return i.filename
 }
 
+// GetPatches returns the kustomize patch files that were applied to this
+// resource, if it was built from a kustomization.
+func (i Resource) GetPatches() []string {
+	return i.patches
+}
+
+// GetLine returns the line on which this resource begins within the file (or
+// stream) from which it was originally decoded, or 0 if unknown.
+func (i Resource) GetLine() int {
+	return i.line
+}
+
+// GetEndLine returns the line on which this resource ends within the file (or
+// stream) from which it was originally decoded, or 0 if unknown.
+func (i Resource) GetEndLine() int {
+	return i.endLine
+}
+
+// GetDocumentIndex returns the index of the yaml/json document containing this
+// resource within the file (or stream) from which it was originally decoded.
+// Resources contained in the same v1.List share a document index. Only
+// meaningful if GetLine returns a non-zero value.
+func (i Resource) GetDocumentIndex() int {
+	return i.document
+}
+
+// GetSource returns the label of the source from which this resource was
+// decoded, if one was given using WithSource.
+func (i Resource) GetSource() string {
+	return i.source
+}
+
+// IsRemoved returns true if this resource only exists in a diff baseline, and
+// was emitted to represent its removal.
+func (i Resource) IsRemoved() bool {
+	return i.removed
+}
+
+// AsRemoved returns a copy of this resource which is marked as removed.
+func (i Resource) AsRemoved() Resource {
+	i.removed = true
+
+	return i
+}
+
 // ResourceFunc is a callback function that is passed each resource encountered
 // while decoding.
 type ResourceFunc func(Resource)
@@ -59,31 +149,59 @@ type ResourceFunc func(Resource)
 //
 // Behavior notes:
 // - If the string "" or "-" is given, resources are read from os.Stdin.
-func Decode(source any, handler ResourceFunc) error {
+// - If a directory containing a "Chart.yaml" file is given, the Helm chart is
+// rendered.
+// - If a directory is given along with WithKustomize, the kustomization is
+// built.
+// - If a git revision and path (like "main:./manifests" or "main:.") is
+// given, resources are decoded from that path as it existed at that revision.
+// - If a file (or stdin) is compressed using gzip, zstd, or bzip2, it is
+// decompressed. If it is a tar or zip archive, it is walked as a directory,
+// and resources are given filenames like "bundle.tgz!/path/in/archive.yaml".
+// - If WithSource is given, every resource is labelled with that source name.
+func Decode(source any, handler ResourceFunc, opts ...Option) error {
+	// Label every resource with the configured source name.
+	if name := newOptions(opts).source; name != "" {
+		labelHandler := handler
+		handler = func(item Resource) {
+			item.source = name
+			labelHandler(item)
+		}
+	}
+
 	switch s := source.(type) {
 	case io.Reader:
-		return Reader(s, handler)
+		return Reader(s, handler, opts...)
 
 	case string:
 		switch s {
 		case "":
 			if term.IsTerminal(int(os.Stdin.Fd())) {
-				return Directory(".", handler)
+				return decodeDirectory(".", handler, opts)
 			}
 
-			return Reader(os.Stdin, handler)
+			return Reader(os.Stdin, handler, opts...)
 
 		case "-":
-			return Reader(os.Stdin, handler)
+			return Reader(os.Stdin, handler, opts...)
 
 		default:
 			if fi, err := os.Stat(s); err != nil {
+				// Sources that do not exist might instead refer to a path at
+				// a git revision, like "main:./manifests". The path must be
+				// given explicitly, so that a missing directory which happens
+				// to share a name with a revision (like "main") is not
+				// mistaken for the entire repository.
+				if revision, path, found := strings.Cut(s, ":"); found && path != "" && errors.Is(err, os.ErrNotExist) && IsRevision(revision) {
+					return Revision(revision, path, handler, opts...)
+				}
+
 				return err
 			} else if fi.IsDir() {
-				return Directory(s, handler)
+				return decodeDirectory(s, handler, opts)
 			}
 
-			return File(s, handler)
+			return File(s, handler, opts...)
 		}
 
 	default:
@@ -91,126 +209,488 @@ func Decode(source any, handler ResourceFunc) error {
 	}
 }
 
+// decodeDirectory decodes Kubernetes resources from the given directory, which
+// is either built as a kustomization, rendered as a Helm chart, or otherwise
+// walked.
+func decodeDirectory(directory string, handler ResourceFunc, opts []Option) error {
+	if newOptions(opts).kustomize {
+		return Kustomization(directory, handler, opts...)
+	}
+
+	if isChart(diskFS{}, directory) {
+		return Chart(directory, handler, opts...)
+	}
+
+	return Directory(directory, handler, opts...)
+}
+
+// renameFiles returns a ResourceFunc and options which rename the filenames
+// (and patch filenames) of each resource, along with the filename of each
+// diagnostic, using the given rename function. Used when resources are decoded
+// from a temporary directory, but should be reported using their original
+// names.
+func renameFiles(handler ResourceFunc, opts []Option, rename func(string) string) (ResourceFunc, []Option) {
+	if diagnostics := newOptions(opts).diagnostics; diagnostics != nil {
+		opts = append(slices.Clip(opts), WithDiagnostics(func(diagnostic Diagnostic) {
+			diagnostic.Filename = rename(diagnostic.Filename)
+			diagnostics(diagnostic)
+		}))
+	}
+
+	return func(item Resource) {
+		if item.filename != "" {
+			item.filename = rename(item.filename)
+		}
+
+		for i, patch := range item.patches {
+			item.patches[i] = rename(patch)
+		}
+
+		handler(item)
+	}, opts
+}
+
 // Directory decodes Kubernetes resources from files discovered while walking
 // the given directory. The ResourceFunc callback is executed with each decoded
 // resource.
 //
 // Behavior notes:
-// - Any directories named ".git" or "node_modules" are skipped.
-// - Any files not ending with ".yaml" are skipped.
-// - Any decoding errors are ignored.
-func Directory(directory string, handler ResourceFunc) error {
-	return filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
-		switch {
-		case err != nil:
-			// There was an actual error.
-			return err
+// - Any directories named ".git" or "node_modules" are skipped, as are any
+// files or directories excluded by WithExclude.
+// - Any files or directories ignored by a .gitignore or .krfignore file within
+// the walked directory are skipped, unless WithoutIgnoreFiles is given.
+// - Any directories containing a Helm chart are rendered, and not walked.
+// - Any files not ending with ".yaml" are skipped, unless WithInclude is
+// given.
+// - Any symlinked directories are skipped, unless WithFollowSymlinks is
+// given.
+// - Any decoding errors are ignored, unless WithDiagnostics is given in which
+// case they are reported.
+// - Files are decoded concurrently, but resources are still passed to the
+// ResourceFunc callback (from a single goroutine) in the order that the files
+// were walked.
+func Directory(directory string, handler ResourceFunc, opts ...Option) error {
+	return walkDirectory(diskFS{}, directory, handler, opts)
+}
 
-		case info.IsDir():
-			// Ignore anything that isn't a file.
-			switch info.Name() {
-			case ".git":
-				// Completely stop recursing into the .git directory.
-				return filepath.SkipDir
-			case "node_modules":
-				// Completely stop recursing into the node_modules directory.
-				return filepath.SkipDir
-			default:
-				return nil
-			}
+// walkDirectory decodes Kubernetes resources from files discovered while
+// walking the given directory of the given walkFS.
+func walkDirectory(fsys walkFS, directory string, handler ResourceFunc, opts []Option) error {
+	o := newOptions(opts)
 
-		case !strings.HasSuffix(info.Name(), ".yaml"):
-			// Ignore anything that isn't a .yaml file.
-			return nil
+	walker, err := newWalker(fsys, o.walk)
+	if err != nil {
+		return err
+	}
 
-		default:
-			_ = File(path, handler)
+	pool := parallel.New(0, func(task decodeTask) decodeResult {
+		return task.decode(opts)
+	}, func(result decodeResult) {
+		for _, item := range result.items {
+			handler(item)
+		}
 
-			// Intentionally do not propagate errors encountered while decoding
-			// a discovered file. Walking through an arbitrary directory can
-			// commonly result in the attempted decoding of invalid yaml files
-			// (Helm charts for example). This should not interrupt the
-			// continued walking of the directory tree.
-			return nil
+		if o.diagnostics != nil {
+			for _, diagnostic := range result.diagnostics {
+				o.diagnostics(diagnostic)
+			}
 		}
 	})
+	defer pool.Wait()
+
+	return walker.walk(directory, func(path string) error {
+		if isChart(fsys, path) {
+			// Render the chart instead of decoding each template file, as
+			// templates are generally not valid yaml. Errors are ignored for
+			// the same reason as with individual files below.
+			pool.Submit(decodeTask{fsys: fsys, path: path, chart: true})
+
+			return filepath.SkipDir
+		}
+
+		return nil
+	}, func(path string) {
+		pool.Submit(decodeTask{fsys: fsys, path: path})
+	})
+}
+
+// decodeTask is a single file (or Helm chart) discovered while walking a
+// directory of the given walkFS.
+type decodeTask struct {
+	fsys  walkFS
+	path  string
+	chart bool
+}
+
+// decodeResult holds the resources and diagnostics from a single decodeTask.
+type decodeResult struct {
+	items       []Resource
+	diagnostics []Diagnostic
+}
+
+// decode decodes every resource from the file (or Helm chart).
+func (t decodeTask) decode(opts []Option) decodeResult {
+	var result decodeResult
+
+	// Diagnostics are collected alongside the resources, so that both are
+	// reported in order.
+	if newOptions(opts).diagnostics != nil {
+		opts = append(slices.Clip(opts), WithDiagnostics(func(diagnostic Diagnostic) {
+			result.diagnostics = append(result.diagnostics, diagnostic)
+		}))
+	}
+
+	decode := t.decodeFile
+	if t.chart {
+		decode = func(handler ResourceFunc, opts []Option) error {
+			return decodeChart(t.fsys, t.path, handler, opts)
+		}
+	}
+
+	// Intentionally do not propagate errors encountered while decoding a
+	// discovered file. Walking through an arbitrary directory can commonly
+	// result in the attempted decoding of invalid yaml files (Helm charts
+	// for example). This should not interrupt the continued walking of the
+	// directory tree.
+	if err := decode(func(item Resource) {
+		result.items = append(result.items, item)
+	}, opts); err != nil {
+		result.diagnostics = append(result.diagnostics, Diagnostic{Filename: t.path, Reason: err.Error()})
+	}
+
+	return result
+}
+
+// decodeFile decodes every resource from the file.
+func (t decodeTask) decodeFile(handler ResourceFunc, opts []Option) error {
+	file, err := t.fsys.open(t.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close() //nolint:errcheck
+
+	return decodeStream(file, t.path, handler, opts)
 }
 
 // File decodes Kubernetes resources from the given filename. The ResourceFunc
 // callback is executed with each decoded resource.
-func File(filename string, handler ResourceFunc) error {
+func File(filename string, handler ResourceFunc, opts ...Option) error {
 	file, err := os.Open(filename)
 	if err != nil {
 		return err
 	}
 	defer file.Close() //nolint:errcheck
 
-	return Reader(file, func(item Resource) {
-		item.filename = filename
-		handler(item)
-	})
+	return decodeStream(file, filename, handler, opts)
 }
 
 // Reader decodes Kubernetes resources from the given io.Reader. The
 // ResourceFunc callback is executed with each decoded resource.
-func Reader(reader io.Reader, handler ResourceFunc) error {
-	return decodeReader(reader, func(uu unstructured.Unstructured) {
-		handler(Resource{Unstructured: uu})
-	})
+//
+// Behavior notes:
+// - Any documents that are not Kubernetes resources (or that are missing a
+// name) are skipped.
+// - Any invalid yaml aborts decoding, unless WithDiagnostics is given in which
+// case the broken document is reported and skipped.
+func Reader(reader io.Reader, handler ResourceFunc, opts ...Option) error {
+	return decodeStream(reader, "", handler, opts)
+}
+
+// decodeStream decodes Kubernetes resources from the given io.Reader, which
+// was read from the given filename (if any). Streams compressed using gzip,
+// zstd, or bzip2 are decompressed, and tar or zip archives are extracted and
+// walked.
+func decodeStream(reader io.Reader, filename string, handler ResourceFunc, opts []Option) error {
+	buffered := bufio.NewReader(reader)
+
+	stream, compressed, err := decompress(buffered)
+	if err != nil {
+		return err
+	}
+	defer stream.Close() //nolint:errcheck
+
+	if compressed {
+		buffered = bufio.NewReader(stream)
+
+		// Resources from within a compressed file cannot be edited in place.
+		packedHandler := handler
+		handler = func(item Resource) {
+			item.packed = true
+			packedHandler(item)
+		}
+	}
+
+	if format := archiveFormat(buffered); format != "" {
+		name := filename
+		if name == "" {
+			name = stdinName
+		}
+
+		return decodeArchive(buffered, format, name, handler, opts)
+	}
+
+	var report func(int, string)
+
+	if diagnostics := newOptions(opts).diagnostics; diagnostics != nil {
+		report = func(line int, reason string) {
+			diagnostics(Diagnostic{Filename: filename, Line: line, Reason: reason})
+		}
+	}
+
+	return decodeReader(buffered, func(uu unstructured.Unstructured, pos position) {
+		handler(Resource{
+			Unstructured: uu,
+			filename:     filename,
+			line:         pos.line,
+			endLine:      pos.endLine,
+			document:     pos.document,
+		})
+	}, report)
 }
 
-func decodeReader(reader io.Reader, handler func(unstructured.Unstructured)) error {
-	decoder := yaml.NewYAMLOrJSONDecoder(reader, 100) //nolint:mnd
+// position describes where a single decoded object was located within a file
+// or stream.
+type position struct {
+	line     int
+	endLine  int
+	document int
+}
+
+// decodeReader decodes every object from the given yaml/json stream. If the
+// given report callback is not nil, it is executed with the line and reason
+// for every document that is skipped, and invalid documents no longer abort
+// decoding.
+func decodeReader(reader io.Reader, handler func(unstructured.Unstructured, position), report func(int, string)) error {
+	buffered := bufio.NewReader(reader)
+
+	// Streams of json objects are decoded incrementally, rather than one
+	// (potentially very large) document at a time.
+	if isJSONStream(buffered) {
+		return decodeJSONStream(buffered, handler, report)
+	}
+
+	documents := documentReader{reader: buffered}
+
+	var index int
 
 	for {
-		// Attempt to decode a single object from the stream.
-		var uu unstructured.Unstructured
-		if err := decoder.Decode(&uu.Object); err != nil {
+		// Read the next yaml document from the stream, while keeping track of
+		// the lines that it spans.
+		document, pos, err := documents.next()
+		if err != nil {
 			if errors.Is(err, io.EOF) {
-				// No more objects in the stream.
+				// No more documents in the stream.
 				return nil
 			}
 
 			return err
 		}
 
-		// Verify that this object has the minimum set of properties to even be
-		// considered a Kubernetes resource.
-		// We don't resourceCheck the name value as that is omitted from v1.List
-		// resources.
-		// We don't resourceCheck the namespace value as that is commonly omitted from
-		// local manifests.
-		if uu.GetAPIVersion() == "" || uu.GetKind() == "" {
-			continue
+		var skip func(string)
+
+		if report != nil {
+			duplicateKeys(document, documents.offset, report)
+
+			skip = func(reason string) {
+				report(pos.line, reason)
+			}
 		}
 
-		// This object does not contain a list of other objects. Handle object
-		// directly.
-		if !uu.IsList() {
-			// Verify that this (non-v1.List) object has a name value.
-			if uu.GetName() == "" {
-				continue
+		// A single yaml document can itself contain a stream of json objects,
+		// each of which is counted as a separate document.
+		count, err := decodeDocument(document, func(uu unstructured.Unstructured, object int) {
+			pos.document = index + object
+			handler(uu, pos)
+		}, skip)
+		if err != nil {
+			if report == nil {
+				return err
 			}
 
-			handler(uu)
+			report(invalidDocument(document, documents.offset, pos, err))
+		}
+
+		index += count
+	}
+}
+
+// decodeDocument decodes every object from the given yaml/json document, and
+// returns the number of (non-empty) objects that were found. If the given skip
+// callback is not nil, it is executed with the reason for every object that is
+// not a Kubernetes resource.
+func decodeDocument(document []byte, handler func(unstructured.Unstructured, int), skip func(string)) (int, error) {
+	if skip == nil {
+		skip = func(string) {}
+	}
+
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(document), 100) //nolint:mnd
+
+	for count := 0; ; count++ {
+		// Attempt to decode a single object from the stream.
+		var uu unstructured.Unstructured
+		for uu.Object == nil {
+			if err := decoder.Decode(&uu.Object); err != nil {
+				if errors.Is(err, io.EOF) {
+					// No more objects in the stream.
+					return count, nil
+				}
+
+				return count, err
+			}
+		}
+
+		if err := decodeObject(uu, func(object unstructured.Unstructured) {
+			handler(object, count)
+		}, skip); err != nil {
+			return count, err
+		}
+	}
+}
+
+// decodeObject verifies that the given object is a Kubernetes resource (or a
+// v1.List of resources) and executes the given handler with each resource. The
+// given skip callback is executed with the reason for every object that is not
+// a Kubernetes resource.
+func decodeObject(uu unstructured.Unstructured, handler func(unstructured.Unstructured), skip func(string)) error {
+	// Verify that this object has the minimum set of properties to even be
+	// considered a Kubernetes resource.
+	// We don't resourceCheck the name value as that is omitted from v1.List
+	// resources.
+	// We don't resourceCheck the namespace value as that is commonly omitted from
+	// local manifests.
+	if uu.GetAPIVersion() == "" || uu.GetKind() == "" {
+		skip(missingFields(uu))
+
+		return nil
+	}
+
+	// This object does not contain a list of other objects. Handle object
+	// directly.
+	if !uu.IsList() {
+		// Verify that this (non-v1.List) object has a name value.
+		if uu.GetName() == "" {
+			skip(uu.GetKind() + " resource is missing a name")
+
+			return nil
+		}
+
+		handler(uu)
+
+		return nil
+	}
+
+	// This object does contain a list of other objects. Handle each
+	// contained object individually.
+	ul, err := uu.ToList()
+	if err != nil {
+		return err
+	}
+
+	for _, uli := range ul.Items {
+		// Verify that this (v1.List item) object has a name value.
+		if uli.GetName() == "" {
+			skip(uli.GetKind() + " resource in " + uu.GetKind() + " is missing a name")
 
 			continue
 		}
 
-		// This object does contain a list of other objects. Handle each
-		// contained object individually.
-		ul, err := uu.ToList()
-		if err != nil {
-			return err
+		handler(uli)
+	}
+
+	return nil
+}
+
+// missingFields returns the reason why the given object is not considered to
+// be a Kubernetes resource.
+func missingFields(uu unstructured.Unstructured) string {
+	switch {
+	case uu.GetAPIVersion() == "" && uu.GetKind() == "":
+		return "not a Kubernetes resource: missing apiVersion and kind"
+	case uu.GetAPIVersion() == "":
+		return "not a Kubernetes resource: missing apiVersion"
+	default:
+		return "not a Kubernetes resource: missing kind"
+	}
+}
+
+// documentReader splits a stream into individual yaml documents, while
+// keeping track of the lines that each document spans.
+type documentReader struct {
+	reader *bufio.Reader
+	line   int
+	done   bool
+
+	// offset is the number of lines in the stream preceding the most recently
+	// returned document.
+	offset int
+}
+
+// next returns the next non-empty document in the stream, along with the
+// first and last lines of that document which are not blank or comments.
+func (r *documentReader) next() ([]byte, position, error) {
+	var (
+		buffer bytes.Buffer
+		pos    position
+	)
+
+	for !r.done {
+		text, err := r.reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, position{}, err
 		}
 
-		for _, uli := range ul.Items {
-			// Verify that this (v1.List item) object has a name value.
-			if uli.GetName() == "" {
-				continue
+		r.done = err != nil
+
+		if len(text) == 0 {
+			continue
+		}
+
+		r.line++
+
+		trimmed := bytes.TrimSpace(text)
+
+		switch {
+		case isDocumentSeparator(text):
+			// Skip over any documents that are completely empty.
+			if pos.line != 0 {
+				return buffer.Bytes(), pos, nil
+			}
+
+			buffer.Reset()
+
+			continue
+
+		case len(trimmed) != 0 && trimmed[0] != '#':
+			if pos.line == 0 {
+				pos.line = r.line
 			}
 
-			handler(uli)
+			pos.endLine = r.line
 		}
+
+		if buffer.Len() == 0 {
+			r.offset = r.line - 1
+		}
+
+		buffer.Write(text)
 	}
+
+	if pos.line == 0 {
+		return nil, position{}, io.EOF
+	}
+
+	return buffer.Bytes(), pos, nil
+}
+
+// isDocumentSeparator reports if the given line is a yaml document separator,
+// optionally followed by a comment.
+func isDocumentSeparator(line []byte) bool {
+	rest, found := bytes.CutPrefix(line, []byte("---"))
+	if !found {
+		return false
+	}
+
+	rest = bytes.TrimSpace(rest)
+
+	return len(rest) == 0 || rest[0] == '#'
 }
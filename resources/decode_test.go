@@ -7,6 +7,8 @@ package resources_test
 
 import (
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/joshdk/krf/resources"
@@ -15,6 +17,7 @@ import (
 func TestDecode(t *testing.T) { //nolint:funlen
 	tests := map[string]struct {
 		source   string
+		options  []resources.Option
 		setup    func() func()
 		expected []resourceCheck
 	}{
@@ -90,6 +93,80 @@ func TestDecode(t *testing.T) { //nolint:funlen
 			},
 		},
 
+		"chart": {
+			source: "testdata/chart",
+			expected: []resourceCheck{
+				{
+					name:     "Deployment/release-name-example",
+					filename: "testdata/chart/templates/deployment.yaml",
+				},
+				{
+					name:     "Service/release-name-example",
+					filename: "testdata/chart/templates/deployment.yaml",
+				},
+			},
+		},
+
+		"chart with values": {
+			source:  "testdata/chart",
+			options: []resources.Option{resources.WithHelmValues("testdata/chart-values.yaml")},
+			expected: []resourceCheck{
+				{
+					name:     "ConfigMap/release-name-example",
+					filename: "testdata/chart/templates/configmap.yaml",
+				},
+				{
+					name:     "Deployment/release-name-example",
+					filename: "testdata/chart/templates/deployment.yaml",
+				},
+				{
+					name:     "Service/release-name-example",
+					filename: "testdata/chart/templates/deployment.yaml",
+				},
+			},
+		},
+
+		"kustomize": {
+			source:  "testdata/kustomize/overlay",
+			options: []resources.Option{resources.WithKustomize()},
+			expected: []resourceCheck{
+				{
+					name:     "Deployment/prod-backend",
+					filename: "testdata/kustomize/base/deployment.yaml",
+					patches:  []string{"testdata/kustomize/overlay/replicas.patch.yaml"},
+				},
+				{
+					name:     "Service/prod-backend",
+					filename: "testdata/kustomize/base/service.yaml",
+					patches:  []string{"testdata/kustomize/overlay/kustomization.yaml"},
+				},
+				{
+					name:     "ConfigMap/prod-settings-ck5fk26hc4",
+					filename: "testdata/kustomize/overlay/kustomization.yaml",
+				},
+			},
+		},
+
+		"kustomize with name prefix": {
+			source:  "testdata/kustomize-names/overlay",
+			options: []resources.Option{resources.WithKustomize()},
+			expected: []resourceCheck{
+				{
+					name:     "Deployment/web-api",
+					filename: "testdata/kustomize-names/base/deployments.yaml",
+					patches:  []string{"testdata/kustomize-names/overlay/replicas.patch.yaml"},
+				},
+				{
+					name:     "Deployment/web-web-api",
+					filename: "testdata/kustomize-names/base/deployments.yaml",
+				},
+				{
+					name:     "ConfigMap/web-settings",
+					filename: "testdata/kustomize-names/base/deployments.yaml",
+				},
+			},
+		},
+
 		"stdin": {
 			source: "-",
 			setup: func() func() {
@@ -122,7 +199,7 @@ func TestDecode(t *testing.T) { //nolint:funlen
 
 			err := resources.Decode(test.source, func(item resources.Resource) {
 				items = append(items, item)
-			})
+			}, test.options...)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -135,6 +212,7 @@ func TestDecode(t *testing.T) { //nolint:funlen
 type resourceCheck struct {
 	name     string
 	filename string
+	patches  []string
 }
 
 func requireResources(t *testing.T, items []resources.Resource, checks []resourceCheck) {
@@ -154,6 +232,302 @@ func requireResources(t *testing.T, items []resources.Resource, checks []resourc
 			t.Fatalf("expected name %s, got %s", check.name, name)
 		case check.filename != item.GetFilename():
 			t.Fatalf("expected filename %s, got %s", check.filename, item.GetFilename())
+		case !slices.Equal(check.patches, item.GetPatches()):
+			t.Fatalf("expected patches %v, got %v", check.patches, item.GetPatches())
 		}
 	}
 }
+
+func TestDecodePositions(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		source   any
+		expected []resourcePosition
+	}{
+		"yaml": {
+			source: "testdata/multiple.yaml",
+			expected: []resourcePosition{
+				{line: 1, endLine: 21, document: 0},
+				{line: 23, endLine: 33, document: 1},
+				{line: 35, endLine: 41, document: 2},
+			},
+		},
+
+		"json": {
+			source: "testdata/multiple.json",
+			expected: []resourcePosition{
+				{line: 1, endLine: 38, document: 0},
+				{line: 39, endLine: 57, document: 1},
+				{line: 58, endLine: 68, document: 2},
+			},
+		},
+
+		"json list": {
+			source: strings.NewReader(`{
+  "apiVersion": "v1",
+  "items": [
+    {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "first"}},
+    {
+      "apiVersion": "v1",
+      "kind": "ConfigMap",
+      "metadata": {"name": "second"}
+    }
+  ],
+  "kind": "List"
+}
+{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "third"}}
+`),
+			expected: []resourcePosition{
+				{line: 4, endLine: 4, document: 0},
+				{line: 5, endLine: 9, document: 0},
+				{line: 13, endLine: 13, document: 1},
+			},
+		},
+
+		"list": {
+			source: "testdata/list.yaml",
+			expected: []resourcePosition{
+				{line: 1, endLine: 45, document: 0},
+				{line: 1, endLine: 45, document: 0},
+				{line: 1, endLine: 45, document: 0},
+			},
+		},
+
+		"comments and empty documents": {
+			source: strings.NewReader(`# header
+---
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+# trailing comment
+--- # separator comment
+
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+`),
+			expected: []resourcePosition{
+				{line: 4, endLine: 7, document: 0},
+				{line: 11, endLine: 14, document: 1},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual []resourcePosition
+
+			err := resources.Decode(test.source, func(item resources.Resource) {
+				actual = append(actual, resourcePosition{
+					line:     item.GetLine(),
+					endLine:  item.GetEndLine(),
+					document: item.GetDocumentIndex(),
+				})
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(test.expected, actual) {
+				t.Fatalf("expected positions %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+type resourcePosition struct {
+	line     int
+	endLine  int
+	document int
+}
+
+func TestDecodeSource(t *testing.T) {
+	t.Parallel()
+
+	for _, opts := range [][]resources.Option{nil, {resources.WithSource("prod")}} {
+		var expected string
+		if len(opts) > 0 {
+			expected = "prod"
+		}
+
+		err := resources.Decode("testdata/multiple.yaml", func(item resources.Resource) {
+			if item.GetSource() != expected {
+				t.Errorf("expected source %q, got %q", expected, item.GetSource())
+			}
+		}, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDecodeDiagnostics(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	stream := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+---
+# Not a resource.
+foo: bar
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  namespace: default
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+data:
+  key: one
+  key: two
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+	name: tabs
+---
+apiVersion: v1
+kind: ConfigMap
+metadata: [
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Secret
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: third
+`
+
+	expected := []string{
+		"(stdin):7: not a Kubernetes resource: missing apiVersion and kind",
+		"(stdin):9: ConfigMap resource is missing a name",
+		"(stdin):20: duplicate key \"key\"",
+		"(stdin):25: invalid yaml: tab character used for indentation",
+		"(stdin):29: invalid yaml: did not find expected node content",
+		"(stdin):31: Secret resource in List is missing a name",
+	}
+
+	var (
+		names       []string
+		diagnostics []string
+	)
+
+	err := resources.Decode(strings.NewReader(stream), func(item resources.Resource) {
+		names = append(names, item.GetName())
+	}, resources.WithDiagnostics(func(diagnostic resources.Diagnostic) {
+		diagnostics = append(diagnostics, diagnostic.String())
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal([]string{"first", "second", "third"}, names) {
+		t.Errorf("expected resources [first second third], got %v", names)
+	}
+
+	if !slices.Equal(expected, diagnostics) {
+		t.Errorf("expected diagnostics:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(diagnostics, "\n"))
+	}
+
+	// Invalid yaml still aborts decoding without diagnostics.
+	if err := resources.Decode(strings.NewReader(stream), func(resources.Resource) {}); err == nil {
+		t.Error("expected an error")
+	}
+
+	diagnostics = nil
+
+	jsonStream := `{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "first"}},
+  {"apiVersion": "v1", "kind": "Secret", "metadata": {}},
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "second", "name": "again"}}
+]}
+{"foo": "bar"}
+{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "third"}},
+`
+
+	expected = []string{
+		"(stdin):3: Secret resource in List is missing a name",
+		"(stdin):4: duplicate key \"name\"",
+		"(stdin):6: not a Kubernetes resource: missing apiVersion and kind",
+		"(stdin):7: invalid json: invalid character ',' looking for beginning of value",
+	}
+
+	err = resources.Decode(strings.NewReader(jsonStream), func(resources.Resource) {}, resources.WithDiagnostics(func(diagnostic resources.Diagnostic) {
+		diagnostics = append(diagnostics, diagnostic.String())
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(expected, diagnostics) {
+		t.Errorf("expected diagnostics:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(diagnostics, "\n"))
+	}
+
+	// Invalid json still aborts decoding without diagnostics.
+	if err := resources.Decode(strings.NewReader(jsonStream), func(resources.Resource) {}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestDecodeKustomizationDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	var diagnostics []string
+
+	err := resources.Decode("testdata/kustomize-names/overlay", func(resources.Resource) {},
+		resources.WithKustomize(),
+		resources.WithDiagnostics(func(diagnostic resources.Diagnostic) {
+			diagnostics = append(diagnostics, diagnostic.String())
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Kustomize accepts documents without an apiVersion, which are reported
+	// against the original resource file (once).
+	expected := []string{
+		"testdata/kustomize-names/base/deployments.yaml:15: not a Kubernetes resource: missing apiVersion",
+	}
+
+	if !slices.Equal(expected, diagnostics) {
+		t.Errorf("expected diagnostics:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(diagnostics, "\n"))
+	}
+}
+
+func TestDecodeChartDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	var diagnostics []string
+
+	err := resources.Decode("testdata/chart", func(resources.Resource) {},
+		resources.WithDiagnostics(func(diagnostic resources.Diagnostic) {
+			diagnostics = append(diagnostics, diagnostic.String())
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"testdata/chart/templates/settings.yaml: not a Kubernetes resource: missing apiVersion and kind",
+	}
+
+	if !slices.Equal(expected, diagnostics) {
+		t.Errorf("expected diagnostics:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(diagnostics, "\n"))
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Diagnostic describes a single document (or file) that was skipped while
+// decoding, because it was either broken or did not contain a Kubernetes
+// resource.
+type Diagnostic struct {
+	// Filename is the file containing the document. This value is empty if
+	// the document was decoded from an io.Reader.
+	Filename string
+
+	// Line is the (1-indexed) line within the file or stream where the
+	// problem was found, or 0 if unknown.
+	Line int
+
+	// Reason describes the problem.
+	Reason string
+}
+
+// stdinName is used in place of a filename for problems found (and archives
+// read) on stdin.
+const stdinName = "(stdin)"
+
+// String returns the diagnostic formatted like "file.yaml:12: reason".
+func (d Diagnostic) String() string {
+	filename := d.Filename
+	if filename == "" {
+		filename = stdinName
+	}
+
+	if d.Line == 0 {
+		return filename + ": " + d.Reason
+	}
+
+	return fmt.Sprintf("%s:%d: %s", filename, d.Line, d.Reason)
+}
+
+// DiagnosticFunc is a callback function that is passed each diagnostic
+// encountered while decoding.
+type DiagnosticFunc func(Diagnostic)
+
+// yamlLinePattern matches yaml syntax errors, which reference a line relative
+// to the start of the document.
+var yamlLinePattern = regexp.MustCompile(`^(?:error converting YAML to JSON: )?yaml: line (\d+): (.*)$`) //nolint:gochecknoglobals
+
+// invalidDocument returns the line and reason describing the given error that
+// was encountered while decoding the given document, which begins after the
+// given number of lines within its file or stream.
+func invalidDocument(document []byte, offset int, pos position, err error) (int, string) {
+	match := yamlLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return pos.line, "invalid yaml: " + err.Error()
+	}
+
+	line, _ := strconv.Atoi(match[1])
+
+	// Tab characters are not allowed in yaml indentation, but are otherwise
+	// reported by the yaml parser with an unhelpful message.
+	if lines := bytes.Split(document, []byte("\n")); line > 0 && line <= len(lines) {
+		text := lines[line-1]
+		if indent := text[:len(text)-len(bytes.TrimLeft(text, " \t"))]; bytes.ContainsRune(indent, '\t') {
+			return offset + line, "invalid yaml: tab character used for indentation"
+		}
+	}
+
+	return offset + line, "invalid yaml: " + match[2]
+}
+
+// duplicateKeys reports the line and name of every duplicate mapping key
+// within the given yaml document, which begins after the given number of
+// lines within its file or stream. Duplicate keys are otherwise silently
+// discarded while decoding, with only the last value being kept.
+func duplicateKeys(document []byte, offset int, report func(int, string)) {
+	node, err := yaml.Parse(string(document))
+	if err != nil {
+		// Syntax errors are reported when the document is decoded.
+		return
+	}
+
+	var walk func(*yaml.Node)
+
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			seen := make(map[string]bool)
+
+			for index := 0; index+1 < len(node.Content); index += 2 {
+				key := node.Content[index]
+				if key.Kind == yaml.ScalarNode && key.Value != "<<" {
+					if seen[key.Value] {
+						report(offset+key.Line, fmt.Sprintf("duplicate key %q", key.Value))
+					}
+
+					seen[key.Value] = true
+				}
+			}
+		}
+
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+
+	walk(node.YNode())
+}
@@ -0,0 +1,250 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// EditFunc modifies the given yaml node of a single resource in place.
+type EditFunc func(node *yaml.RNode) error
+
+// Edit applies the given EditFunc to each of the given resources, and rewrites
+// the modified documents to the files from which the resources were originally
+// decoded. The edited resources are returned, with updated contents and lines.
+//
+// Behavior notes:
+// - Only the documents containing the given resources are rewritten. Comments,
+// key ordering, and all other documents within the same file are preserved.
+// - Resources that were not decoded directly from a yaml file (like those read
+// from stdin, rendered from a Helm chart, or decoded from a git revision)
+// cannot be edited.
+// - Resources that were built from a kustomization are edited within their
+// original base file.
+func Edit(items []Resource, edit EditFunc) ([]Resource, error) {
+	filenames, files, err := groupByFile(items, "edit")
+	if err != nil {
+		return nil, err
+	}
+
+	edited := slices.Clone(items)
+
+	for _, filename := range filenames {
+		if err := editFile(filename, edited, files[filename], edit); err != nil {
+			return nil, fmt.Errorf("editing %s: %w", filename, err)
+		}
+	}
+
+	return edited, nil
+}
+
+// editFile applies the given EditFunc to the resources (at the given indexes)
+// that were decoded from the given file, and rewrites that file.
+func editFile(filename string, items []Resource, indexes []int, edit EditFunc) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(string(contents), "\n")
+
+	sortByLineDescending(items, indexes)
+
+	for start := 0; start < len(indexes); {
+		// Resources contained in the same v1.List share the same document.
+		end := start + sameDocument(items, indexes[start:])
+
+		first := items[indexes[start]]
+		if first.endLine > len(lines) {
+			return errors.New("file has changed since it was decoded")
+		}
+
+		replacement, err := editDocument(lines[first.line-1:first.endLine], items, indexes[start:end], edit)
+		if err != nil {
+			return err
+		}
+
+		shift := len(replacement) - (first.endLine - first.line + 1)
+		lines = slices.Replace(lines, first.line-1, first.endLine, replacement...)
+
+		// Update the lines of the edited resources, as well as those of the
+		// resources later in the file that were already edited.
+		for _, index := range indexes[start:end] {
+			items[index].endLine += shift
+		}
+
+		for _, index := range indexes[:start] {
+			items[index].line += shift
+			items[index].endLine += shift
+		}
+
+		start = end
+	}
+
+	if err := os.WriteFile(filename, []byte(strings.Join(lines, "")), info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	forgetSource(filename)
+
+	return nil
+}
+
+// editDocument applies the given EditFunc to the resources (at the given
+// indexes) contained in the given document lines, and returns the replacement
+// document lines.
+func editDocument(lines []string, items []Resource, indexes []int, edit EditFunc) ([]string, error) {
+	text := strings.Join(lines, "")
+
+	if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return nil, errors.New("editing json documents is not supported")
+	}
+
+	document, err := yaml.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, index := range indexes {
+		item := &items[index]
+
+		node := findNode(document, *item)
+		if node == nil {
+			return nil, fmt.Errorf("%s/%s: could not find resource in document", item.GetKind(), item.GetName())
+		}
+
+		if err := edit(node); err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", item.GetKind(), item.GetName(), err)
+		}
+
+		// Also apply the edit to the resource object itself, which might
+		// differ from the document (like when built from a kustomization).
+		object, err := yaml.FromMap(item.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := edit(object); err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", item.GetKind(), item.GetName(), err)
+		}
+
+		// Round-trip through json, so that numbers are decoded as int64 as
+		// expected by unstructured.Unstructured.
+		data, err := object.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+
+		var uu unstructured.Unstructured
+		if err := uu.UnmarshalJSON(data); err != nil {
+			return nil, err
+		}
+
+		item.Unstructured = uu
+	}
+
+	return encodeDocument(document, text)
+}
+
+// groupByFile groups the indexes of the given resources by the file from which
+// each was decoded, and returns the list of files in the order first seen. An
+// error is returned for resources that cannot be modified in their file.
+func groupByFile(items []Resource, action string) ([]string, map[string][]int, error) {
+	var filenames []string
+
+	files := make(map[string][]int)
+
+	for index, item := range items {
+		switch {
+		case item.removed:
+			return nil, nil, fmt.Errorf("%s/%s: cannot %s a removed resource", item.GetKind(), item.GetName(), action)
+		case item.filename == "" || item.line == 0:
+			return nil, nil, fmt.Errorf("%s/%s: cannot %s a resource that was not decoded from a file", item.GetKind(), item.GetName(), action)
+		case item.packed:
+			return nil, nil, fmt.Errorf("%s/%s: cannot %s a resource that was decoded from a compressed file or archive", item.GetKind(), item.GetName(), action)
+		}
+
+		if _, found := files[item.filename]; !found {
+			filenames = append(filenames, item.filename)
+		}
+
+		files[item.filename] = append(files[item.filename], index)
+	}
+
+	return filenames, files, nil
+}
+
+// encodeDocument encodes the given yaml document, using the same sequence
+// indentation style as the original document text, and returns the resulting
+// lines.
+func encodeDocument(document *yaml.RNode, text string) ([]string, error) {
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoderWithOptions(&buffer, &yaml.EncoderOptions{
+		SeqIndent: yaml.SequenceIndentStyle(yaml.DeriveSeqIndentStyle(text)),
+	})
+
+	if err := encoder.Encode(document.Document()); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	result := buffer.String()
+
+	// Preserve a missing trailing newline at the end of the file.
+	if !strings.HasSuffix(text, "\n") {
+		result = strings.TrimSuffix(result, "\n")
+	}
+
+	return splitLines(result), nil
+}
+
+// splitLines splits the given text into lines, each of which retains its
+// trailing newline.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// sortByLineDescending sorts the given resource indexes from the bottom of the
+// file upwards, so that the lines of the documents that are yet to be modified
+// remain unchanged.
+func sortByLineDescending(items []Resource, indexes []int) {
+	slices.SortStableFunc(indexes, func(a, b int) int {
+		return items[b].line - items[a].line
+	})
+}
+
+// sameDocument returns the number of leading resource indexes that share the
+// same document (like those contained in a v1.List) as the first.
+func sameDocument(items []Resource, indexes []int) int {
+	count := 1
+	for count < len(indexes) && items[indexes[count]].line == items[indexes[0]].line {
+		count++
+	}
+
+	return count
+}
@@ -0,0 +1,127 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+
+	"github.com/joshdk/krf/resources"
+)
+
+func TestEdit(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	original := `# A deployment.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web # the service
+spec:
+  ports:
+    - port: 80
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: first
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: second`
+
+	filename := filepath.Join(t.TempDir(), "resources.yaml")
+	if err := os.WriteFile(filename, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var items []resources.Resource
+
+	if err := resources.Decode(filename, func(item resources.Resource) {
+		if item.GetName() != "web" || item.GetKind() == "Service" {
+			items = append(items, item)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a label to every resource, which adds two lines to each.
+	edited, err := resources.Edit(items, func(node *yaml.RNode) error {
+		return node.PipeE(yaml.SetLabel("edited", "true"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# A deployment.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web # the service
+  labels:
+    edited: 'true'
+spec:
+  ports:
+    - port: 80
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: first
+    labels:
+      edited: 'true'
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: second
+    labels:
+      edited: 'true'`
+
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, string(contents)); diff != "" {
+		t.Errorf("unexpected file contents (-want +got):\n%s", diff)
+	}
+
+	for index, lines := range [][2]int{{7, 15}, {17, 31}, {17, 31}} {
+		item := edited[index]
+
+		if item.GetLine() != lines[0] || item.GetEndLine() != lines[1] {
+			t.Errorf("expected %s/%s to span lines %d-%d, got %d-%d", item.GetKind(), item.GetName(), lines[0], lines[1], item.GetLine(), item.GetEndLine())
+		}
+
+		if item.GetLabels()["edited"] != "true" {
+			t.Errorf("expected %s/%s to be labelled", item.GetKind(), item.GetName())
+		}
+	}
+
+	if _, err := resources.Edit([]resources.Resource{{}}, func(*yaml.RNode) error { return nil }); err == nil {
+		t.Error("expected an error for a resource without a file")
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ExtractFunc is a callback function that is passed each extracted resource,
+// along with its original yaml text.
+type ExtractFunc func(item Resource, text string) error
+
+// Extract removes each of the given resources from the file from which it was
+// originally decoded. The ExtractFunc callback is executed with each resource
+// and its original yaml text (including any comments), before the file
+// containing that resource is rewritten.
+//
+// Behavior notes:
+// - Comments and all other documents within the same file are preserved.
+// Comments immediately before or after an extracted document are considered
+// part of that document, and are extracted along with it.
+// - Resources contained in a v1.List are removed from the list, and the list
+// itself is removed once empty.
+// - Files left empty after every resource has been extracted are deleted.
+// - Resources that were not decoded directly from a yaml file (like those read
+// from stdin, rendered from a Helm chart, or decoded from a git revision)
+// cannot be extracted.
+func Extract(items []Resource, handler ExtractFunc) error {
+	filenames, files, err := groupByFile(items, "extract")
+	if err != nil {
+		return err
+	}
+
+	for _, filename := range filenames {
+		if err := extractFile(filename, items, files[filename], handler); err != nil {
+			return fmt.Errorf("extracting from %s: %w", filename, err)
+		}
+	}
+
+	return nil
+}
+
+// extractFile removes the resources (at the given indexes) that were decoded
+// from the given file, and rewrites that file.
+func extractFile(filename string, items []Resource, indexes []int, handler ExtractFunc) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(string(contents), "\n")
+
+	indexes = slices.Clone(indexes)
+	sortByLineDescending(items, indexes)
+
+	// Extracted resources are passed to the handler in their original order.
+	texts := make(map[int]string)
+
+	for start := 0; start < len(indexes); {
+		// Resources contained in the same v1.List share the same document.
+		end := start + sameDocument(items, indexes[start:])
+
+		first := items[indexes[start]]
+		if first.endLine > len(lines) {
+			return errors.New("file has changed since it was decoded")
+		}
+
+		if lines, err = extractDocument(lines, first.line-1, first.endLine, items, indexes[start:end], texts); err != nil {
+			return err
+		}
+
+		start = end
+	}
+
+	slices.Sort(indexes)
+
+	for _, index := range indexes {
+		if err := handler(items[index], texts[index]); err != nil {
+			return err
+		}
+	}
+
+	forgetSource(filename)
+
+	remaining := strings.Join(lines, "")
+	if strings.TrimSpace(remaining) == "" {
+		return os.Remove(filename)
+	}
+
+	return os.WriteFile(filename, []byte(remaining), info.Mode().Perm())
+}
+
+// extractDocument removes the resources (at the given indexes) contained in
+// the document spanning the given lines, and returns the remaining lines. The
+// original yaml text of each resource is stored in the given texts.
+func extractDocument(lines []string, start, end int, items []Resource, indexes []int, texts map[int]string) ([]string, error) {
+	text := strings.Join(lines[start:end], "")
+
+	if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return nil, errors.New("extracting json documents is not supported")
+	}
+
+	document, err := yaml.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := document.Pipe(yaml.Lookup("items"))
+	if err != nil {
+		return nil, err
+	}
+
+	// The document contains a single resource, so remove the entire document.
+	if list == nil {
+		start, end = documentSegment(lines, start, end)
+		texts[indexes[0]] = trimBlankLines(strings.Join(lines[start:end], ""))
+
+		return removeSegment(lines, start, end), nil
+	}
+
+	// Otherwise, remove the individual resources from the v1.List.
+	for _, index := range indexes {
+		node := findNode(document, items[index])
+		if node == nil {
+			return nil, fmt.Errorf("%s/%s: could not find resource in document", items[index].GetKind(), items[index].GetName())
+		}
+
+		element, err := encodeDocument(node, text)
+		if err != nil {
+			return nil, err
+		}
+
+		texts[index] = trimBlankLines(strings.Join(element, ""))
+
+		list.YNode().Content = slices.DeleteFunc(list.YNode().Content, func(element *yaml.Node) bool {
+			return element == node.YNode()
+		})
+	}
+
+	// Remove the entire document once the v1.List is empty.
+	if len(list.YNode().Content) == 0 {
+		start, end = documentSegment(lines, start, end)
+
+		return removeSegment(lines, start, end), nil
+	}
+
+	replacement, err := encodeDocument(document, text)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Replace(lines, start, end, replacement...), nil
+}
+
+// documentSegment expands the given document lines to include any comments
+// or blank lines up to the surrounding document separators.
+func documentSegment(lines []string, start, end int) (int, int) {
+	for start > 0 && !isDocumentSeparator([]byte(lines[start-1])) {
+		start--
+	}
+
+	for end < len(lines) && !isDocumentSeparator([]byte(lines[end])) {
+		end++
+	}
+
+	return start, end
+}
+
+// removeSegment removes the given document segment, along with one of the
+// surrounding document separators.
+func removeSegment(lines []string, start, end int) []string {
+	switch {
+	case end < len(lines):
+		// Remove the following separator.
+		end++
+	case start > 0:
+		// Remove the preceding separator, as this is the last document.
+		start--
+	}
+
+	return slices.Delete(lines, start, end)
+}
+
+// trimBlankLines removes any leading and trailing blank lines from the given
+// text, which is then terminated with a newline.
+func trimBlankLines(text string) string {
+	lines := splitLines(text)
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.TrimSuffix(strings.Join(lines, ""), "\n") + "\n"
+}
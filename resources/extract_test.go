@@ -0,0 +1,148 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/joshdk/krf/resources"
+)
+
+func TestExtract(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	original := `# The deployment.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+# The service.
+apiVersion: v1
+kind: Service
+metadata:
+  name: web # the service
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: first # the first
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: second
+`
+
+	directory := t.TempDir()
+
+	filename := filepath.Join(directory, "resources.yaml")
+	if err := os.WriteFile(filename, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	decode := func(names ...string) []resources.Resource {
+		var items []resources.Resource
+
+		if err := resources.Decode(filename, func(item resources.Resource) {
+			for _, name := range names {
+				if item.GetKind()+"/"+item.GetName() == name {
+					items = append(items, item)
+				}
+			}
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		return items
+	}
+
+	extract := func(items []resources.Resource) map[string]string {
+		texts := make(map[string]string)
+
+		if err := resources.Extract(items, func(item resources.Resource, text string) error {
+			texts[item.GetKind()+"/"+item.GetName()] = text
+
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		return texts
+	}
+
+	requireContents := func(expected string) {
+		t.Helper()
+
+		contents, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff(expected, string(contents)); diff != "" {
+			t.Errorf("unexpected file contents (-want +got):\n%s", diff)
+		}
+	}
+
+	// Extract a single document, and a single list item.
+	texts := extract(decode("Service/web", "ConfigMap/first"))
+
+	expectedTexts := map[string]string{
+		"Service/web":     "# The service.\napiVersion: v1\nkind: Service\nmetadata:\n  name: web # the service\n",
+		"ConfigMap/first": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: first # the first\n",
+	}
+
+	if diff := cmp.Diff(expectedTexts, texts); diff != "" {
+		t.Errorf("unexpected extracted texts (-want +got):\n%s", diff)
+	}
+
+	requireContents(`# The deployment.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: second
+`)
+
+	// Extract the last list item, which removes the entire list.
+	extract(decode("ConfigMap/second"))
+
+	requireContents(`# The deployment.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+
+	// A failing handler leaves the file untouched.
+	err := resources.Extract(decode("Deployment/web"), func(resources.Resource, string) error {
+		return errors.New("failed")
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// Extracting the last resource deletes the file.
+	extract(decode("Deployment/web"))
+
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be deleted", filename)
+	}
+}
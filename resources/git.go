@@ -0,0 +1,199 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-billy/v6/osfs"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// SplitRevision splits a source like "main:./manifests" into a git revision
+// and a path. A source without a path, like "HEAD~1", refers to the current
+// directory.
+func SplitRevision(source string) (string, string) {
+	if revision, path, found := strings.Cut(source, ":"); found {
+		return revision, path
+	}
+
+	return source, "."
+}
+
+// IsRevision reports if the given revision can be resolved in the git
+// repository containing the current directory.
+func IsRevision(revision string) bool {
+	repository, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return false
+	}
+
+	_, err = repository.ResolveRevision(plumbing.Revision(revision))
+
+	return err == nil
+}
+
+// Revision decodes Kubernetes resources from the given path (a file or a
+// directory) as it existed at the given git revision. The ResourceFunc
+// callback is executed with each decoded resource.
+//
+// Behavior notes:
+// - Files are read directly from the git object database, so no checkout is
+// needed, and the worktree is not modified.
+// - As with git, paths starting with "./" or "../" are relative to the current
+// directory, while other paths are relative to the root of the repository.
+// - The filename of each resource is set to the revision and path of the
+// original file, like "main:manifests/deployment.yaml".
+// - Directories are walked (or rendered as a Helm chart) in the same way as
+// on disk. When using WithKustomize, files are read on demand from the entire
+// tree, so that kustomizations can refer to files outside the given
+// directory.
+func Revision(revision, path string, handler ResourceFunc, opts ...Option) error {
+	repository, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return err
+	}
+
+	root, err := repositoryRoot(repository)
+	if err != nil {
+		return err
+	}
+
+	relPath, err := repositoryPath(root, path)
+	if err != nil {
+		return err
+	}
+
+	hash, err := repository.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return fmt.Errorf("resolving git revision %q: %w", revision, err)
+	}
+
+	commit, err := repository.CommitObject(*hash)
+	if err != nil {
+		return err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	// revisionName returns the name of the given file (relative to the root
+	// of the repository) at the current revision.
+	revisionName := func(name string) string {
+		return revision + ":" + filepath.ToSlash(name)
+	}
+
+	if relPath != "." {
+		entry, err := tree.FindEntry(relPath)
+		if err != nil {
+			return fmt.Errorf("%s: %w", revisionName(relPath), err)
+		}
+
+		// Decode a single file directly.
+		if entry.Mode.IsFile() {
+			file, err := tree.File(relPath)
+			if err != nil {
+				return err
+			}
+
+			reader, err := file.Reader()
+			if err != nil {
+				return err
+			}
+			defer reader.Close() //nolint:errcheck
+
+			return decodeStream(reader, revisionName(relPath), handler, opts)
+		}
+	}
+
+	// Build kustomizations using the entire tree, so that they can refer to
+	// files outside the given directory. The tree is mounted at the root of
+	// an in-memory file system.
+	if newOptions(opts).kustomize {
+		directory := filepath.Join(filesys.Separator, filepath.FromSlash(relPath))
+
+		handler, opts = renameFiles(handler, opts, func(filename string) string {
+			return revisionName(strings.TrimPrefix(filepath.ToSlash(filename), "/"))
+		})
+
+		return buildKustomization(newTreeFileSystem(tree), directory, directory, filepath.Join(filesys.Separator, ".krf-kustomize"), handler, opts)
+	}
+
+	fsys := newTreeFS(tree)
+	directory := filepath.FromSlash(relPath)
+
+	// Report resources and diagnostics using names at the current revision,
+	// rather than paths within the tree.
+	handler, opts = renameFiles(handler, opts, revisionName)
+
+	if isChart(fsys, directory) {
+		return decodeChart(fsys, directory, handler, opts)
+	}
+
+	return walkDirectory(fsys, directory, handler, opts)
+}
+
+// repositoryRoot returns the root directory of the given repository's
+// worktree.
+func repositoryRoot(repository *git.Repository) (string, error) {
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	filesystem, ok := worktree.Filesystem.(*osfs.BoundOS)
+	if !ok {
+		return "", errors.New("could not determine git repo directory")
+	}
+
+	return filepath.EvalSymlinks(filesystem.Root())
+}
+
+// repositoryPath returns the given path relative to the given repository root
+// directory, using the same rules as git. Paths that are absolute, or that
+// start with "./" or "../", are relative to the current directory, while
+// other paths are already relative to the repository root.
+func repositoryPath(root, name string) (string, error) {
+	switch {
+	case filepath.IsAbs(name):
+	case name == "." || name == "..":
+	case strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../"):
+	default:
+		return path.Clean(filepath.ToSlash(name)), nil
+	}
+
+	absPath, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+
+	// Resolve symlinks in the longest existing prefix of the path, so that it
+	// can be compared against the (also resolved) repository root.
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	} else if resolved, err := filepath.EvalSymlinks(filepath.Dir(absPath)); err == nil {
+		absPath = filepath.Join(resolved, filepath.Base(absPath))
+	}
+
+	relPath, err := filepath.Rel(root, absPath)
+	if err != nil {
+		return "", err
+	}
+
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", fmt.Errorf("path %s is outside of the git repository", name)
+	}
+
+	return filepath.ToSlash(relPath), nil
+}
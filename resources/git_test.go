@@ -0,0 +1,179 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+
+	"github.com/joshdk/krf/resources"
+)
+
+func TestRevision(t *testing.T) { //nolint:funlen
+	testdata, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a repository containing a copy of the test data, with the
+	// directory example being deleted in a second commit.
+	directory := t.TempDir()
+
+	repository, err := git.PlainInit(directory, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit := func(message string) {
+		if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := worktree.Commit(message, &git.CommitOptions{
+			AllowEmptyCommits: true,
+			Author:            &object.Signature{Name: "test", When: time.Now()},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"chart", "directory", "kustomize"} {
+		if err := os.CopyFS(filepath.Join(directory, name), os.DirFS(filepath.Join(testdata, name))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commit("first")
+
+	if err := os.RemoveAll(filepath.Join(directory, "directory")); err != nil {
+		t.Fatal(err)
+	}
+
+	commit("second")
+
+	t.Chdir(filepath.Join(directory, "kustomize"))
+
+	tests := map[string]struct {
+		source   string
+		options  []resources.Option
+		expected []resourceCheck
+	}{
+		"file": {
+			source: "HEAD~1:directory/multiple.yaml",
+			expected: []resourceCheck{
+				{
+					name:     "Deployment/nginx-deployment",
+					filename: "HEAD~1:directory/multiple.yaml",
+				},
+				{
+					name:     "Service/my-service",
+					filename: "HEAD~1:directory/multiple.yaml",
+				},
+			},
+		},
+
+		"directory": {
+			source: "HEAD~1:../directory",
+			expected: []resourceCheck{
+				{
+					name:     "Deployment/nginx-deployment",
+					filename: "HEAD~1:directory/multiple.yaml",
+				},
+				{
+					name:     "Service/my-service",
+					filename: "HEAD~1:directory/multiple.yaml",
+				},
+				{
+					name:     "ConfigMap/myconfigmap",
+					filename: "HEAD~1:directory/subdirectory/single.yaml",
+				},
+			},
+		},
+
+		"chart": {
+			source: "HEAD:chart",
+			expected: []resourceCheck{
+				{
+					name:     "Deployment/release-name-example",
+					filename: "HEAD:chart/templates/deployment.yaml",
+				},
+				{
+					name:     "Service/release-name-example",
+					filename: "HEAD:chart/templates/deployment.yaml",
+				},
+			},
+		},
+
+		"kustomize": {
+			source:  "HEAD:./overlay",
+			options: []resources.Option{resources.WithKustomize()},
+			expected: []resourceCheck{
+				{
+					name:     "Deployment/prod-backend",
+					filename: "HEAD:kustomize/base/deployment.yaml",
+					patches:  []string{"HEAD:kustomize/overlay/replicas.patch.yaml"},
+				},
+				{
+					name:     "Service/prod-backend",
+					filename: "HEAD:kustomize/base/service.yaml",
+					patches:  []string{"HEAD:kustomize/overlay/kustomization.yaml"},
+				},
+				{
+					name:     "ConfigMap/prod-settings-ck5fk26hc4",
+					filename: "HEAD:kustomize/overlay/kustomization.yaml",
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var items []resources.Resource
+
+			err := resources.Decode(test.source, func(item resources.Resource) {
+				items = append(items, item)
+			}, test.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			requireResources(t, items, test.expected)
+		})
+	}
+
+	for _, source := range []string{"HEAD", "HEAD:", "HEAD:directory", "HEAD:../..", "missing:./overlay"} {
+		err := resources.Decode(source, func(resources.Resource) {})
+		if err == nil {
+			t.Errorf("expected an error for source %q", source)
+		}
+	}
+}
+
+func TestSplitRevision(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][2]string{
+		"HEAD~1":             {"HEAD~1", "."},
+		"main:./manifests":   {"main", "./manifests"},
+		"v1.0.0:deploy.yaml": {"v1.0.0", "deploy.yaml"},
+	}
+
+	for source, expected := range tests {
+		if revision, path := resources.SplitRevision(source); revision != expected[0] || path != expected[1] {
+			t.Errorf("expected %q to split into %q and %q, got %q and %q", source, expected[0], expected[1], revision, path)
+		}
+	}
+}
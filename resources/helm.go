@@ -0,0 +1,117 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Chart decodes Kubernetes resources by rendering the Helm chart located in
+// the given directory. The ResourceFunc callback is executed with each
+// decoded resource.
+//
+// Behavior notes:
+// - Charts are rendered offline, with the release name "release-name" and the
+// release namespace "default" (the same defaults used by "helm template").
+// - Values files can be given using WithHelmValues.
+// - The filename of each resource is set to the template that produced it.
+// - Any decoding errors within the rendered templates are ignored, unless
+// WithDiagnostics is given in which case they are reported.
+func Chart(directory string, handler ResourceFunc, opts ...Option) error {
+	return decodeChart(diskFS{}, directory, handler, opts)
+}
+
+// decodeChart renders the Helm chart located in the given directory of the
+// given walkFS.
+func decodeChart(fsys walkFS, directory string, handler ResourceFunc, opts []Option) error {
+	o := newOptions(opts)
+
+	chrt, err := fsys.loadChart(directory)
+	if err != nil {
+		return err
+	}
+
+	// Merge all given values files, with values in later files taking
+	// precedence over values in earlier files.
+	values := map[string]any{}
+
+	for _, filename := range o.helmValues {
+		fileValues, err := chartutil.ReadValuesFile(filename)
+		if err != nil {
+			return err
+		}
+
+		values = chartutil.MergeTables(fileValues, values)
+	}
+
+	if err := chartutil.ProcessDependenciesWithMerge(chrt, values); err != nil {
+		return err
+	}
+
+	renderValues, err := chartutil.ToRenderValues(chrt, values, chartutil.ReleaseOptions{
+		Name:      "release-name",
+		Namespace: "default",
+		Revision:  1,
+		IsInstall: true,
+	}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return err
+	}
+
+	rendered, err := engine.Render(chrt, renderValues)
+	if err != nil {
+		return err
+	}
+
+	// Render templates in a consistent order.
+	for _, name := range slices.Sorted(maps.Keys(rendered)) {
+		content := rendered[name]
+
+		switch {
+		case strings.HasPrefix(path.Base(name), "_"):
+			// Ignore partial templates.
+			continue
+		case path.Base(name) == "NOTES.txt":
+			// Ignore installation notes.
+			continue
+		case strings.TrimSpace(content) == "":
+			// Ignore templates that rendered nothing.
+			continue
+		}
+
+		// Rendered template names are prefixed with the name of the chart
+		// (not the name of the chart directory) so swap that prefix with the
+		// chart directory to produce a real filename.
+		_, relative, _ := strings.Cut(name, "/")
+		filename := filepath.Join(directory, filepath.FromSlash(relative))
+
+		// Lines within the rendered output do not correspond to lines within
+		// the template, so diagnostics only name the template.
+		var report func(int, string)
+
+		if o.diagnostics != nil {
+			report = func(_ int, reason string) {
+				o.diagnostics(Diagnostic{Filename: filename, Reason: reason})
+			}
+		}
+
+		if err := decodeReader(strings.NewReader(content), func(uu unstructured.Unstructured, _ position) {
+			handler(Resource{Unstructured: uu, filename: filename})
+		}, report); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,339 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// isJSONStream reports if the given stream begins with a json object, by
+// peeking at its first few (non-whitespace) bytes. Yaml flow mappings (like
+// "{kind: List}") are not considered json objects, as their keys are not
+// quoted.
+func isJSONStream(reader *bufio.Reader) bool {
+	var seen []byte
+
+	for size := 1; size <= reader.Size(); size++ {
+		data, err := reader.Peek(size)
+		if err != nil {
+			return false
+		}
+
+		switch char := data[size-1]; char {
+		case ' ', '\t', '\r', '\n':
+			continue
+		default:
+			seen = append(seen, char)
+		}
+
+		switch {
+		case seen[0] != '{':
+			return false
+		case len(seen) == 2: //nolint:mnd
+			return seen[1] == '"' || seen[1] == '}'
+		}
+	}
+
+	return false
+}
+
+// decodeJSONStream decodes every object from the given stream of json objects.
+// Unlike yaml documents, the stream is decoded incrementally, and the items
+// within a v1.List are decoded one at a time, so that very large lists (like
+// those produced by "kubectl get -A -o json") are never held in memory in
+// their entirety. If the given report callback is not nil, it is executed
+// with the line and reason for every object that is skipped.
+func decodeJSONStream(reader io.Reader, handler func(unstructured.Unstructured, position), report func(int, string)) error {
+	counter := &lineCounter{reader: reader}
+
+	stream := jsonStream{
+		decoder: json.NewDecoder(counter),
+		counter: counter,
+		handler: handler,
+		report:  report,
+	}
+
+	for index := 0; ; index++ {
+		if err := stream.object(index); err != nil {
+			if errors.Is(err, io.EOF) {
+				// No more objects in the stream.
+				return nil
+			}
+
+			if report == nil {
+				return err
+			}
+
+			// The stream cannot be decoded any further after a syntax error.
+			var line int
+
+			var syntax *json.SyntaxError
+			if errors.As(err, &syntax) {
+				line = counter.line(syntax.Offset)
+			}
+
+			report(line, "invalid json: "+err.Error())
+
+			return nil
+		}
+	}
+}
+
+// jsonStream decodes a stream of json objects.
+type jsonStream struct {
+	decoder *json.Decoder
+	counter *lineCounter
+	handler func(unstructured.Unstructured, position)
+	report  func(int, string)
+}
+
+// skip reports the given reason for skipping an object, if configured.
+func (s jsonStream) skip(line int, reason string) {
+	if s.report != nil {
+		s.report(line, reason)
+	}
+}
+
+// object decodes the next json object from the stream, which is the given
+// document index within the stream.
+func (s jsonStream) object(index int) error {
+	token, err := s.decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != json.Delim('{') {
+		return fmt.Errorf("expected a json object, found %v", token)
+	}
+
+	start := s.counter.line(s.decoder.InputOffset() - 1)
+
+	var (
+		uu       unstructured.Unstructured
+		streamed bool
+	)
+
+	uu.Object = make(map[string]any)
+
+	for s.decoder.More() {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		key, _ := token.(string)
+		if _, found := uu.Object[key]; found {
+			s.skip(s.counter.line(s.decoder.InputOffset()), fmt.Sprintf("duplicate key %q", key))
+		}
+
+		// The items within a v1.List (which has an apiVersion, like those
+		// produced by kubectl) are decoded and handled individually.
+		if key == "items" && uu.GetAPIVersion() != "" {
+			value, streams, err := s.items(func(item unstructured.Unstructured, pos position) {
+				if item.GetName() == "" {
+					kind := uu.GetKind()
+					if kind == "" {
+						kind = "List"
+					}
+
+					s.skip(pos.line, item.GetKind()+" resource in "+kind+" is missing a name")
+
+					return
+				}
+
+				pos.document = index
+				s.handler(item, pos)
+			})
+			if err != nil {
+				return err
+			}
+
+			if streams {
+				streamed = true
+
+				continue
+			}
+
+			uu.Object[key] = value
+
+			continue
+		}
+
+		var value any
+		if err := s.decoder.Decode(&value); err != nil {
+			return err
+		}
+
+		uu.Object[key] = value
+	}
+
+	// Consume the closing brace.
+	if _, err := s.decoder.Token(); err != nil {
+		return err
+	}
+
+	pos := position{
+		line:     start,
+		endLine:  s.counter.line(s.decoder.InputOffset() - 1),
+		document: index,
+	}
+
+	// The items have already been handled, but the v1.List itself is still
+	// verified.
+	if streamed {
+		if uu.GetKind() == "" {
+			s.skip(pos.line, missingFields(uu))
+		}
+
+		return nil
+	}
+
+	return decodeObject(uu, func(object unstructured.Unstructured) {
+		s.handler(object, pos)
+	}, func(reason string) {
+		s.skip(pos.line, reason)
+	})
+}
+
+// items decodes the value of an "items" field. If the value is an array of
+// objects, each object is passed to the given handler and true is returned.
+// Otherwise, the value is returned as is.
+func (s jsonStream) items(handler func(unstructured.Unstructured, position)) (any, bool, error) {
+	token, err := s.decoder.Token()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if token != json.Delim('[') {
+		value, err := decodeJSONValue(s.decoder, token)
+
+		return value, false, err
+	}
+
+	for s.decoder.More() {
+		var raw json.RawMessage
+		if err := s.decoder.Decode(&raw); err != nil {
+			return nil, false, err
+		}
+
+		end := s.decoder.InputOffset()
+		pos := position{
+			line:    s.counter.line(end - int64(len(raw))),
+			endLine: s.counter.line(end - 1),
+		}
+
+		var item unstructured.Unstructured
+		if err := json.Unmarshal(raw, &item.Object); err != nil || item.Object == nil {
+			return nil, false, errors.New("items member is not an object")
+		}
+
+		if s.report != nil {
+			duplicateKeys(raw, pos.line-1, s.report)
+		}
+
+		handler(item, pos)
+	}
+
+	// Consume the closing bracket.
+	if _, err := s.decoder.Token(); err != nil {
+		return nil, false, err
+	}
+
+	return nil, true, nil
+}
+
+// decodeJSONValue decodes the remainder of a json value, which begins with the
+// given (already consumed) token.
+func decodeJSONValue(decoder *json.Decoder, token json.Token) (any, error) {
+	switch token {
+	case json.Delim('{'):
+		object := make(map[string]any)
+
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			var value any
+			if err := decoder.Decode(&value); err != nil {
+				return nil, err
+			}
+
+			object[fmt.Sprint(key)] = value
+		}
+
+		_, err := decoder.Token()
+
+		return object, err
+
+	case json.Delim('['):
+		array := []any{}
+
+		for decoder.More() {
+			var value any
+			if err := decoder.Decode(&value); err != nil {
+				return nil, err
+			}
+
+			array = append(array, value)
+		}
+
+		_, err := decoder.Token()
+
+		return array, err
+
+	default:
+		return token, nil
+	}
+}
+
+// lineCounter wraps an io.Reader and keeps track of the lines that have been
+// read, so that byte offsets within the stream can be converted into lines.
+type lineCounter struct {
+	reader io.Reader
+
+	// read is the number of bytes read so far, and newlines holds the offset
+	// of each newline that has been read, but not yet passed by a call to
+	// line.
+	read     int64
+	newlines []int64
+	passed   int
+}
+
+func (c *lineCounter) Read(data []byte) (int, error) {
+	n, err := c.reader.Read(data)
+
+	for index, char := range data[:n] {
+		if char == '\n' {
+			c.newlines = append(c.newlines, c.read+int64(index))
+		}
+	}
+
+	c.read += int64(n)
+
+	return n, err
+}
+
+// line returns the (1-indexed) line containing the byte at the given offset.
+// Offsets must not decrease between calls.
+func (c *lineCounter) line(offset int64) int {
+	count := 0
+	for count < len(c.newlines) && c.newlines[count] < offset {
+		count++
+	}
+
+	c.passed += count
+	c.newlines = c.newlines[count:]
+
+	return c.passed + 1
+}
@@ -0,0 +1,443 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// originAnnotation is added by kustomize (when requested) to record the
+	// file, or generator, that each resource originated from.
+	originAnnotation = "config.kubernetes.io/origin"
+
+	// transformationsAnnotation is added by kustomize (when requested) to
+	// record the transformers that were run against each resource.
+	transformationsAnnotation = "alpha.config.kubernetes.io/transformations"
+)
+
+// Kustomization decodes Kubernetes resources by building the kustomization
+// located in the given directory. The ResourceFunc callback is executed with
+// each decoded resource.
+//
+// Behavior notes:
+// - The filename of each resource is set to the base file that it originated
+// from, or the kustomization file that generated it.
+// - The patches of each resource are set to the patch files (or kustomization
+// files containing inline patches) that were applied to it. This is done on
+// a best-effort basis, as kustomize does not record which patches actually
+// modified a resource.
+// - Any decoding errors within the original resource files are ignored, unless
+// WithDiagnostics is given in which case they are reported.
+func Kustomization(directory string, handler ResourceFunc, opts ...Option) error {
+	absDirectory, err := filepath.Abs(directory)
+	if err != nil {
+		return err
+	}
+
+	wrapperDirectory, err := os.MkdirTemp("", "krf-kustomize-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(wrapperDirectory) //nolint:errcheck
+
+	return buildKustomization(filesys.MakeFsOnDisk(), directory, absDirectory, wrapperDirectory, handler, opts)
+}
+
+// buildKustomization builds the kustomization located in the given absolute
+// directory of the given file system, where the filename of each resource is
+// relative to the given (original) directory.
+func buildKustomization(fsys filesys.FileSystem, directory, absDirectory, wrapperDirectory string, handler ResourceFunc, opts []Option) error {
+	// Kustomize only records resource provenance when requested by the
+	// kustomization being built. To avoid modifying the original kustomization,
+	// a temporary kustomization is created (in the given wrapper directory)
+	// which wraps the original one, and makes that request instead.
+	target, err := filepath.Rel(wrapperDirectory, absDirectory)
+	if err != nil {
+		return err
+	}
+
+	wrapper, err := yaml.Marshal(types.Kustomization{
+		Resources:     []string{filepath.ToSlash(target)},
+		BuildMetadata: []string{types.OriginAnnotations, types.TransformerAnnotations},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := fsys.MkdirAll(wrapperDirectory); err != nil {
+		return err
+	}
+
+	if err := fsys.WriteFile(filepath.Join(wrapperDirectory, "kustomization.yaml"), wrapper); err != nil {
+		return err
+	}
+
+	options := krusty.MakeDefaultOptions()
+	options.LoadRestrictions = types.LoadRestrictionsNone
+
+	resmap, err := krusty.MakeKustomizer(options).Run(fsys, wrapperDirectory)
+	if err != nil {
+		return err
+	}
+
+	// originalPath converts a path within the given file system to a path
+	// relative to the original directory.
+	originalPath := func(path string) string {
+		relative, err := filepath.Rel(absDirectory, path)
+		if err != nil {
+			return path
+		}
+
+		return filepath.Join(directory, relative)
+	}
+
+	// sourcePath converts a path, relative to the temporary kustomization, to
+	// a path relative to the original directory.
+	sourcePath := func(path string) string {
+		return originalPath(filepath.Join(wrapperDirectory, path))
+	}
+
+	// Diagnostics from the original resource files are reported using paths
+	// relative to the original directory.
+	_, opts = renameFiles(handler, opts, originalPath)
+
+	cache := kustomizationCache{
+		fsys:           fsys,
+		opts:           opts,
+		kustomizations: make(map[string]*types.Kustomization),
+		patches:        make(map[string][]kustomizationPatch),
+		resources:      make(map[string][]Resource),
+	}
+
+	for _, res := range resmap.Resources() {
+		object, err := res.Map()
+		if err != nil {
+			return err
+		}
+
+		item := Resource{Unstructured: unstructured.Unstructured{Object: object}}
+
+		origin, err := res.GetOrigin()
+		if err != nil {
+			return err
+		}
+
+		var transformations resource.Transformations
+		if err := yaml.Unmarshal([]byte(res.GetAnnotations()[transformationsAnnotation]), &transformations); err != nil {
+			return err
+		}
+
+		// Remove the provenance annotations, as they were not part of the
+		// original resources.
+		annotations := item.GetAnnotations()
+		delete(annotations, originAnnotation)
+		delete(annotations, transformationsAnnotation)
+
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+
+		item.SetAnnotations(annotations)
+
+		// names are the names given to the resource during the build, from
+		// its original name through to its final name.
+		names := []string{item.GetName()}
+
+		switch {
+		case origin == nil:
+			// Resource provenance is unknown.
+		case origin.Path != "":
+			// Resource originated from a file.
+			item.filename = sourcePath(origin.Path)
+
+			affixes := cache.affixes(wrapperDirectory, transformations)
+
+			if original, found := cache.original(filepath.Join(wrapperDirectory, origin.Path), item, affixes); found {
+				names = affixes.names(original.GetName())
+				item.line, item.endLine, item.document = original.line, original.endLine, original.document
+			}
+		case origin.ConfiguredIn != "":
+			// Resource was generated from a kustomization.
+			item.filename = sourcePath(origin.ConfiguredIn)
+		}
+
+		for _, transformation := range transformations {
+			switch transformation.ConfiguredBy.Kind {
+			case "PatchTransformer", "PatchStrategicMergeTransformer", "PatchJson6902Transformer":
+			default:
+				// Ignore anything that isn't a patch.
+				continue
+			}
+
+			kustomizationFile := filepath.Join(wrapperDirectory, transformation.ConfiguredIn)
+
+			for _, patch := range cache.load(kustomizationFile) {
+				if !patch.applies(item, names) {
+					continue
+				}
+
+				filename := sourcePath(transformation.ConfiguredIn)
+				if patch.path != "" {
+					filename = sourcePath(filepath.Join(filepath.Dir(transformation.ConfiguredIn), patch.path))
+				}
+
+				if !slices.Contains(item.patches, filename) {
+					item.patches = append(item.patches, filename)
+				}
+			}
+		}
+
+		handler(item)
+	}
+
+	return nil
+}
+
+// nameAffix is a name prefix or suffix added by a kustomization.
+type nameAffix struct {
+	prefix string
+	suffix string
+}
+
+// nameAffixes are the name prefixes and suffixes added to a resource, in the
+// order that they were added.
+type nameAffixes []nameAffix
+
+// names returns the names given to a resource with the given original name,
+// after each prefix or suffix was added.
+func (a nameAffixes) names(original string) []string {
+	names := []string{original}
+
+	for _, affix := range a {
+		original = affix.prefix + original + affix.suffix
+		names = append(names, original)
+	}
+
+	return names
+}
+
+// affixes returns the name prefixes and suffixes added by the given
+// transformations, relative to the temporary kustomization in the given
+// wrapper directory.
+func (c kustomizationCache) affixes(wrapperDirectory string, transformations resource.Transformations) nameAffixes {
+	var affixes nameAffixes
+
+	for _, transformation := range transformations {
+		kustomization := c.read(filepath.Join(wrapperDirectory, transformation.ConfiguredIn))
+		if kustomization == nil {
+			continue
+		}
+
+		switch transformation.ConfiguredBy.Kind {
+		case "PrefixTransformer":
+			affixes = append(affixes, nameAffix{prefix: kustomization.NamePrefix})
+		case "SuffixTransformer":
+			affixes = append(affixes, nameAffix{suffix: kustomization.NameSuffix})
+		}
+	}
+
+	return affixes
+}
+
+// original returns the given resource as it appears in the given file, before
+// the given name prefixes and suffixes were added.
+func (c kustomizationCache) original(filename string, item Resource, affixes nameAffixes) (Resource, bool) {
+	if _, found := c.resources[filename]; !found {
+		c.resources[filename] = nil
+
+		_ = readResources(c.fsys, filename, func(candidate Resource) {
+			c.resources[filename] = append(c.resources[filename], candidate)
+		}, c.opts)
+	}
+
+	for _, candidate := range c.resources[filename] {
+		names := affixes.names(candidate.GetName())
+
+		if candidate.GetKind() == item.GetKind() && names[len(names)-1] == item.GetName() {
+			return candidate, true
+		}
+	}
+
+	return Resource{}, false
+}
+
+// kustomizationPatch is a single patch declared in a kustomization file.
+type kustomizationPatch struct {
+	// path is the patch filename, relative to the kustomization file. Empty
+	// for inline patches.
+	path string
+
+	// target is the selector for resources that this patch applies to.
+	target *types.Selector
+
+	// documents are the patch documents, used to determine which resources
+	// a patch applies to when no target selector is given.
+	documents []Resource
+}
+
+// applies reports if the given resource would be modified by this patch, where
+// the resource was given each of the given names during the build.
+func (p kustomizationPatch) applies(item Resource, names []string) bool {
+	if p.target == nil {
+		return slices.ContainsFunc(p.documents, func(document Resource) bool {
+			return document.GetKind() == item.GetKind() && slices.Contains(names, document.GetName())
+		})
+	}
+
+	selector, err := types.NewSelectorRegex(p.target)
+	if err != nil {
+		return false
+	}
+
+	gvk := item.GroupVersionKind()
+
+	switch {
+	case !selector.MatchGvk(resid.Gvk{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}):
+		return false
+	case !slices.ContainsFunc(names, selector.MatchName):
+		return false
+	case !selector.MatchNamespace(item.GetNamespace()):
+		return false
+	case !selectorMatches(p.target.LabelSelector, item.GetLabels()):
+		return false
+	case !selectorMatches(p.target.AnnotationSelector, item.GetAnnotations()):
+		return false
+	default:
+		return true
+	}
+}
+
+// selectorMatches reports if the given label selector matches the given set.
+// An empty label selector always matches.
+func selectorMatches(selector string, set map[string]string) bool {
+	if selector == "" {
+		return true
+	}
+
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return false
+	}
+
+	return sel.Matches(labels.Set(set))
+}
+
+// readResources decodes Kubernetes resources from the given file of the given
+// file system.
+func readResources(fsys filesys.FileSystem, filename string, handler ResourceFunc, opts []Option) error {
+	data, err := fsys.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return decodeStream(bytes.NewReader(data), filename, handler, opts)
+}
+
+// kustomizationCache is a cache of kustomization files, the patches declared
+// in them, and the original resource files, all read from the given file
+// system and keyed by filename.
+type kustomizationCache struct {
+	fsys           filesys.FileSystem
+	opts           []Option
+	kustomizations map[string]*types.Kustomization
+	patches        map[string][]kustomizationPatch
+	resources      map[string][]Resource
+}
+
+// read returns the given kustomization file, or nil if it cannot be read.
+func (c kustomizationCache) read(filename string) *types.Kustomization {
+	if kustomization, found := c.kustomizations[filename]; found {
+		return kustomization
+	}
+
+	c.kustomizations[filename] = nil
+
+	data, err := c.fsys.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+
+	var kustomization types.Kustomization
+	if err := kustomization.Unmarshal(data); err != nil {
+		return nil
+	}
+
+	c.kustomizations[filename] = &kustomization
+
+	return &kustomization
+}
+
+// load returns the patches declared in the given kustomization file.
+func (c kustomizationCache) load(filename string) []kustomizationPatch {
+	if patches, found := c.patches[filename]; found {
+		return patches
+	}
+
+	var patches []kustomizationPatch
+
+	defer func() {
+		c.patches[filename] = patches
+	}()
+
+	kustomization := c.read(filename)
+	if kustomization == nil {
+		return nil
+	}
+
+	directory := filepath.Dir(filename)
+
+	// The declared patches are cloned, as the cached kustomization is shared.
+	declared := slices.Clone(kustomization.Patches)
+
+	// Inline strategic merge patches are indistinguishable from patch
+	// filenames, aside from the fact that a patch file exists.
+	for _, patch := range kustomization.PatchesStrategicMerge {
+		if c.fsys.Exists(filepath.Join(directory, string(patch))) {
+			declared = append(declared, types.Patch{Path: string(patch)})
+		} else {
+			declared = append(declared, types.Patch{Patch: string(patch)})
+		}
+	}
+
+	for _, patch := range append(declared, kustomization.PatchesJson6902...) {
+		current := kustomizationPatch{
+			path:   patch.Path,
+			target: patch.Target,
+		}
+
+		// Patches without a target selector identify the resources they apply
+		// to using their own contents.
+		if patch.Target == nil {
+			handler := func(document Resource) {
+				current.documents = append(current.documents, document)
+			}
+
+			if patch.Path != "" {
+				_ = readResources(c.fsys, filepath.Join(directory, patch.Path), handler, nil)
+			} else {
+				_ = Reader(strings.NewReader(patch.Patch), handler)
+			}
+		}
+
+		patches = append(patches, current)
+	}
+
+	return patches
+}
@@ -0,0 +1,159 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+// Option configures optional decoding behavior.
+type Option func(*options)
+
+// options holds the combined configuration from a list of Option values.
+type options struct {
+	// helmValues is a list of values files used when rendering Helm charts.
+	helmValues []string
+
+	// kustomize configures directories to be built as kustomizations.
+	kustomize bool
+
+	// source is the label given to every decoded resource.
+	source string
+
+	// walk configures how directories are walked.
+	walk walkOptions
+
+	// archive limits how much is extracted from each archive.
+	archive archiveLimits
+
+	// diagnostics is passed each document that is skipped while decoding.
+	diagnostics DiagnosticFunc
+}
+
+// walkOptions configures how directories are walked.
+type walkOptions struct {
+	// include is a list of globs for the files that are decoded.
+	include []string
+
+	// exclude is a list of globs for the files and directories that are
+	// skipped.
+	exclude []string
+
+	// noIgnoreFiles disables reading .gitignore and .krfignore files.
+	noIgnoreFiles bool
+
+	// followSymlinks configures symlinked directories to be walked.
+	followSymlinks bool
+
+	// maxDepth limits how many directories deep are walked.
+	maxDepth int
+}
+
+// archiveLimits limits how much is extracted from each archive.
+type archiveLimits struct {
+	// maxBytes is the total number of bytes extracted, or 0 for the default.
+	maxBytes int64
+
+	// maxEntries is the number of files extracted, or 0 for the default.
+	maxEntries int
+}
+
+// newOptions returns the combined configuration from the given Option list.
+func newOptions(opts []Option) options {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithHelmValues configures a list of values files to use when rendering Helm
+// charts. Values in later files take precedence over those in earlier files,
+// as well as over the chart's own default values.
+func WithHelmValues(filenames ...string) Option {
+	return func(o *options) {
+		o.helmValues = append(o.helmValues, filenames...)
+	}
+}
+
+// WithKustomize configures directories to be built as kustomizations, instead
+// of being walked.
+func WithKustomize() Option {
+	return func(o *options) {
+		o.kustomize = true
+	}
+}
+
+// WithSource configures a label (like "prod") that is given to every decoded
+// resource, in order to identify the source from which it was decoded when
+// decoding from multiple sources.
+func WithSource(name string) Option {
+	return func(o *options) {
+		o.source = name
+	}
+}
+
+// WithInclude configures a list of globs for the files that are decoded while
+// walking a directory, instead of only files ending with ".yaml". Globs that
+// contain a "/" are matched against the path relative to the walked directory,
+// and otherwise against the file name.
+func WithInclude(globs ...string) Option {
+	return func(o *options) {
+		o.walk.include = append(o.walk.include, globs...)
+	}
+}
+
+// WithExclude configures a list of globs for the files and directories that
+// are skipped while walking a directory. Globs are matched in the same way as
+// with WithInclude.
+func WithExclude(globs ...string) Option {
+	return func(o *options) {
+		o.walk.exclude = append(o.walk.exclude, globs...)
+	}
+}
+
+// WithoutIgnoreFiles configures any .gitignore and .krfignore files to be
+// disregarded while walking a directory.
+func WithoutIgnoreFiles() Option {
+	return func(o *options) {
+		o.walk.noIgnoreFiles = true
+	}
+}
+
+// WithFollowSymlinks configures symlinked directories to be walked, instead of
+// being skipped.
+func WithFollowSymlinks() Option {
+	return func(o *options) {
+		o.walk.followSymlinks = true
+	}
+}
+
+// WithMaxDepth configures how many directories deep are walked, where a depth
+// of 1 only includes the files directly within the walked directory. A depth
+// of 0 is unlimited.
+func WithMaxDepth(depth int) Option {
+	return func(o *options) {
+		o.walk.maxDepth = depth
+	}
+}
+
+// WithArchiveLimits configures the total number of bytes, and the number of
+// files, that are extracted from each archive before decoding fails. A limit
+// of 0 uses the default of 1GiB or 100,000 files respectively.
+func WithArchiveLimits(maxBytes int64, maxEntries int) Option {
+	return func(o *options) {
+		o.archive = archiveLimits{maxBytes: maxBytes, maxEntries: maxEntries}
+	}
+}
+
+// WithDiagnostics configures a callback which is passed a Diagnostic for every
+// document that is skipped while decoding, either because it is not a
+// Kubernetes resource, or because it is broken. Invalid yaml documents are
+// also skipped (instead of aborting decoding) and files that cannot be decoded
+// while walking a directory are reported (instead of being ignored).
+func WithDiagnostics(handler DiagnosticFunc) Option {
+	return func(o *options) {
+		o.diagnostics = handler
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// sourceCache holds the lines of each file, as resources commonly share the
+// same file.
+var sourceCache = struct { //nolint:gochecknoglobals
+	sync.Mutex
+	files map[string][]string
+}{files: make(map[string][]string)}
+
+// SourceLines returns the original lines (from GetLine to GetEndLine) of the
+// file from which the given resource was decoded. Nothing is returned for
+// resources that have no known lines, or whose file can no longer be read.
+func SourceLines(item Resource) []string {
+	if item.filename == "" || item.line == 0 {
+		return nil
+	}
+
+	sourceCache.Lock()
+	defer sourceCache.Unlock()
+
+	lines, found := sourceCache.files[item.filename]
+	if !found {
+		// Files that cannot be read (like those decoded from a git revision,
+		// or from within an archive) are cached as having no lines.
+		if contents, err := readFile(item.filename); err == nil {
+			lines = strings.Split(string(contents), "\n")
+		}
+
+		sourceCache.files[item.filename] = lines
+	}
+
+	if item.endLine > len(lines) {
+		return nil
+	}
+
+	return lines[item.line-1 : item.endLine]
+}
+
+// SourceNode parses the original source lines of the given resource (see
+// SourceLines), and returns the resulting yaml node along with the offset that
+// needs to be added to each node line to produce a line within the original
+// file. Nothing is returned if the source lines are unavailable or cannot be
+// parsed.
+func SourceNode(item Resource) (*yaml.RNode, int) {
+	lines := SourceLines(item)
+	if lines == nil {
+		return nil, 0
+	}
+
+	node, err := yaml.Parse(strings.Join(lines, "\n"))
+	if err != nil {
+		return nil, 0
+	}
+
+	if node = findNode(node, item); node == nil {
+		return nil, 0
+	}
+
+	return node, item.line - 1
+}
+
+// findNode returns the yaml node for the given resource from the given
+// document node. Resources contained in a v1.List share the same document, so
+// the list item for that particular resource is returned.
+func findNode(node *yaml.RNode, item Resource) *yaml.RNode {
+	if node.GetKind() == item.GetKind() && node.GetName() == item.GetName() {
+		return node
+	}
+
+	items, err := node.Pipe(yaml.Lookup("items"))
+	if err != nil {
+		return nil
+	}
+
+	// Documents that are not a v1.List contain only a single resource, even
+	// if its name has since been changed (like by a kustomize namePrefix).
+	if items == nil {
+		return node
+	}
+
+	elements, err := items.Elements()
+	if err != nil {
+		return nil
+	}
+
+	for _, element := range elements {
+		if element.GetKind() == item.GetKind() && element.GetName() == item.GetName() {
+			return element
+		}
+	}
+
+	return nil
+}
+
+// forgetSource removes the given file from the source and blame caches, as it
+// has been modified.
+func forgetSource(filename string) {
+	sourceCache.Lock()
+	delete(sourceCache.files, filename)
+	sourceCache.Unlock()
+
+	blameCache.Lock()
+	delete(blameCache.files, filename)
+	blameCache.Unlock()
+}
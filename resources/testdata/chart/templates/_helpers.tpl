@@ -0,0 +1,3 @@
+{{- define "example.fullname" -}}
+{{ .Release.Name }}-{{ .Chart.Name }}
+{{- end }}
@@ -0,0 +1,385 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/ignore"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// treeFS is a walkFS for the tree of a git commit, which reads files directly
+// from the git object database. Paths are relative to the root of the tree.
+// Symlinks and submodules are skipped.
+//
+// A tree caches its subtrees without any locking, so every method holds the
+// lock, as the tree is shared between the walker and the decoding workers.
+type treeFS struct {
+	tree *object.Tree
+	lock *sync.Mutex
+}
+
+// newTreeFS returns a treeFS for the given tree.
+func newTreeFS(tree *object.Tree) treeFS {
+	return treeFS{tree: tree, lock: &sync.Mutex{}}
+}
+
+// treeName returns the slash separated name of the given path within a tree.
+func treeName(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
+
+// subtree returns the tree for the given directory.
+func (f treeFS) subtree(directory string) (*object.Tree, error) {
+	if name := treeName(directory); name != "." {
+		return f.tree.Tree(name)
+	}
+
+	return f.tree, nil
+}
+
+func (f treeFS) readDir(directory string) ([]fs.DirEntry, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	tree, err := f.subtree(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []fs.DirEntry
+
+	for _, entry := range tree.Entries {
+		if entry, ok := newTreeEntry(entry.Name, entry.Mode); ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return entries, nil
+}
+
+func (f treeFS) stat(name string) (fs.FileInfo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if treeName(name) == "." {
+		return treeEntry{name: ".", mode: fs.ModeDir}, nil
+	}
+
+	entry, err := f.tree.FindEntry(treeName(name))
+	if err != nil {
+		return nil, fs.ErrNotExist
+	}
+
+	info, ok := newTreeEntry(entry.Name, entry.Mode)
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+
+	return info, nil
+}
+
+func (treeFS) realPath(name string) string {
+	return treeName(name)
+}
+
+// open reads the given file while holding the lock, so that the returned
+// reader can be used concurrently with any other method.
+func (f treeFS) open(filename string) (io.ReadCloser, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	file, err := f.tree.File(treeName(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(strings.NewReader(contents)), nil
+}
+
+// loadChart loads the Helm chart in the given directory, skipping any files
+// ignored by a .helmignore file, in the same way as loader.LoadDir.
+func (f treeFS) loadChart(directory string) (*chart.Chart, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	tree, err := f.subtree(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := ignore.Empty()
+
+	if file, err := tree.File(ignore.HelmIgnore); err == nil {
+		contents, err := file.Contents()
+		if err != nil {
+			return nil, err
+		}
+
+		if rules, err = ignore.Parse(strings.NewReader(contents)); err != nil {
+			return nil, err
+		}
+	}
+
+	rules.AddDefaults()
+
+	var files []*loader.BufferedFile
+
+	err = tree.Files().ForEach(func(file *object.File) error {
+		info, ok := newTreeEntry(path.Base(file.Name), file.Mode)
+		if !ok || info.IsDir() {
+			return nil
+		}
+
+		// Skip files that are ignored, or that are within an ignored
+		// directory.
+		for dir := path.Dir(file.Name); dir != "."; dir = path.Dir(dir) {
+			if rules.Ignore(dir, treeEntry{name: path.Base(dir), mode: fs.ModeDir}) {
+				return nil
+			}
+		}
+
+		if rules.Ignore(file.Name, info) {
+			return nil
+		}
+
+		contents, err := file.Contents()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, &loader.BufferedFile{Name: file.Name, Data: []byte(contents)})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.LoadFiles(files)
+}
+
+// treeEntry is a directory or regular file within a git tree, which
+// implements both fs.DirEntry and fs.FileInfo.
+type treeEntry struct {
+	name string
+	mode fs.FileMode
+}
+
+// newTreeEntry returns a treeEntry for the given name and git file mode, along
+// with true if the entry is a directory or regular file.
+func newTreeEntry(name string, mode filemode.FileMode) (treeEntry, bool) {
+	osMode, err := mode.ToOSFileMode()
+	if err != nil || !(osMode.IsDir() || osMode.IsRegular()) {
+		return treeEntry{}, false
+	}
+
+	return treeEntry{name: name, mode: osMode}, true
+}
+
+func (e treeEntry) Name() string               { return e.name }
+func (e treeEntry) IsDir() bool                { return e.mode.IsDir() }
+func (e treeEntry) Type() fs.FileMode          { return e.mode.Type() }
+func (e treeEntry) Info() (fs.FileInfo, error) { return e, nil }
+func (e treeEntry) Size() int64                { return 0 }
+func (e treeEntry) Mode() fs.FileMode          { return e.mode }
+func (e treeEntry) ModTime() time.Time         { return time.Time{} }
+func (e treeEntry) Sys() any                   { return nil }
+
+// treeFileSystem is a kustomize filesys.FileSystem for the tree of a git
+// commit, which is mounted at the root directory of an in-memory file system.
+// Directories and files are read from the git object database on demand, so
+// that only those needed to build a kustomization are read.
+type treeFileSystem struct {
+	filesys.FileSystem
+
+	tree *object.Tree
+
+	// loaded holds each directory whose entries have been added, and pending
+	// holds the tree name of each added file whose contents have not yet
+	// been read.
+	loaded  map[string]bool
+	pending map[string]string
+}
+
+// newTreeFileSystem returns a treeFileSystem for the given tree.
+func newTreeFileSystem(tree *object.Tree) *treeFileSystem {
+	return &treeFileSystem{
+		FileSystem: filesys.MakeFsInMemory(),
+		tree:       tree,
+		loaded:     make(map[string]bool),
+		pending:    make(map[string]string),
+	}
+}
+
+// load adds the entries of each directory along the given path, including
+// the path itself if it is a directory.
+func (f *treeFileSystem) load(name string) {
+	directory := filesys.Separator
+
+	f.loadDir(directory)
+
+	for _, component := range strings.Split(filepath.ToSlash(filepath.Clean(name)), "/") {
+		if component == "" {
+			continue
+		}
+
+		directory = filepath.Join(directory, component)
+		if !f.FileSystem.IsDir(directory) {
+			return
+		}
+
+		f.loadDir(directory)
+	}
+}
+
+// loadDir adds the entries of the given directory. The contents of each file
+// are read later, when the file is first read.
+func (f *treeFileSystem) loadDir(directory string) {
+	if f.loaded[directory] {
+		return
+	}
+
+	f.loaded[directory] = true
+
+	name := strings.TrimPrefix(filepath.ToSlash(directory), "/")
+	if name == "" {
+		name = "."
+	}
+
+	tree, err := newTreeFS(f.tree).subtree(name)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range tree.Entries {
+		info, ok := newTreeEntry(entry.Name, entry.Mode)
+		if !ok {
+			continue
+		}
+
+		// Never replace anything that was already added, like the temporary
+		// kustomization.
+		filename := filepath.Join(directory, entry.Name)
+		if f.FileSystem.Exists(filename) {
+			continue
+		}
+
+		if info.IsDir() {
+			_ = f.FileSystem.Mkdir(filename)
+
+			continue
+		}
+
+		_ = f.FileSystem.WriteFile(filename, nil)
+		f.pending[filename] = path.Join(name, entry.Name)
+	}
+}
+
+// loadAll adds every directory and file within the given directory.
+func (f *treeFileSystem) loadAll(directory string) {
+	f.load(directory)
+
+	names, err := f.FileSystem.ReadDir(directory)
+	if err != nil {
+		return
+	}
+
+	for _, name := range names {
+		if child := filepath.Join(directory, name); f.FileSystem.IsDir(child) {
+			f.loadAll(child)
+		}
+	}
+}
+
+// read adds the given file, along with its contents.
+func (f *treeFileSystem) read(filename string) {
+	f.load(filename)
+
+	filename = filepath.Clean(filename)
+
+	name, found := f.pending[filename]
+	if !found {
+		return
+	}
+
+	delete(f.pending, filename)
+
+	if file, err := f.tree.File(name); err == nil {
+		if contents, err := file.Contents(); err == nil {
+			_ = f.FileSystem.WriteFile(filename, []byte(contents))
+		}
+	}
+}
+
+func (f *treeFileSystem) Open(path string) (filesys.File, error) {
+	f.read(path)
+
+	return f.FileSystem.Open(path)
+}
+
+func (f *treeFileSystem) ReadFile(path string) ([]byte, error) {
+	f.read(path)
+
+	return f.FileSystem.ReadFile(path)
+}
+
+func (f *treeFileSystem) IsDir(path string) bool {
+	f.load(path)
+
+	return f.FileSystem.IsDir(path)
+}
+
+func (f *treeFileSystem) ReadDir(path string) ([]string, error) {
+	f.load(path)
+
+	return f.FileSystem.ReadDir(path)
+}
+
+func (f *treeFileSystem) CleanedAbs(path string) (filesys.ConfirmedDir, string, error) {
+	f.load(path)
+
+	return f.FileSystem.CleanedAbs(path)
+}
+
+func (f *treeFileSystem) Exists(path string) bool {
+	f.load(path)
+
+	return f.FileSystem.Exists(path)
+}
+
+func (f *treeFileSystem) Glob(pattern string) ([]string, error) {
+	f.load(filepath.Dir(pattern))
+
+	return f.FileSystem.Glob(pattern)
+}
+
+func (f *treeFileSystem) Walk(path string, walkFn filepath.WalkFunc) error {
+	f.loadAll(path)
+
+	return f.FileSystem.Walk(path, walkFn)
+}
@@ -0,0 +1,273 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/format/gitignore"
+	"github.com/gobwas/glob"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// ignoreFiles are the names of the files containing gitignore-style patterns
+// for the files and directories that are skipped while walking.
+var ignoreFiles = []string{".gitignore", ".krfignore"} //nolint:gochecknoglobals
+
+// walkFS is a file system that can be walked, which is either the local disk
+// (see diskFS) or the tree of a git commit (see treeFS).
+type walkFS interface {
+	// readDir returns the entries of the given directory, sorted by name.
+	readDir(directory string) ([]fs.DirEntry, error)
+
+	// stat returns information about the given path, following symlinks.
+	stat(name string) (fs.FileInfo, error)
+
+	// realPath returns the given path with any symlinks resolved, or nothing
+	// if it cannot be resolved.
+	realPath(name string) string
+
+	// open opens the given file for reading.
+	open(filename string) (io.ReadCloser, error)
+
+	// loadChart loads the Helm chart located in the given directory.
+	loadChart(directory string) (*chart.Chart, error)
+}
+
+// diskFS is a walkFS for the local disk.
+type diskFS struct{}
+
+func (diskFS) readDir(directory string) ([]fs.DirEntry, error) {
+	return os.ReadDir(directory)
+}
+
+func (diskFS) stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (diskFS) realPath(name string) string {
+	realPath, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		return ""
+	}
+
+	return realPath
+}
+
+func (diskFS) open(filename string) (io.ReadCloser, error) {
+	return os.Open(filename)
+}
+
+func (diskFS) loadChart(directory string) (*chart.Chart, error) {
+	return loader.LoadDir(directory)
+}
+
+// isChart reports if the given directory in the given walkFS contains a Helm
+// chart.
+func isChart(fsys walkFS, directory string) bool {
+	fi, err := fsys.stat(filepath.Join(directory, chartutil.ChartfileName))
+
+	return err == nil && !fi.IsDir()
+}
+
+// pathGlob is a glob that is matched against either the name of a file, or
+// against its slash separated path relative to the walked directory.
+type pathGlob struct {
+	glob glob.Glob
+	path bool
+}
+
+// newPathGlobs compiles the given list of globs.
+func newPathGlobs(patterns []string) ([]pathGlob, error) {
+	globs := make([]pathGlob, len(patterns))
+
+	for index, pattern := range patterns {
+		compiled, err := glob.Compile(pattern, '/')
+		if err != nil {
+			return nil, err
+		}
+
+		globs[index] = pathGlob{glob: compiled, path: strings.Contains(pattern, "/")}
+	}
+
+	return globs, nil
+}
+
+// matchAny reports if any of the given globs match the given path components.
+func matchAny(globs []pathGlob, components []string) bool {
+	return slices.ContainsFunc(globs, func(g pathGlob) bool {
+		if g.path {
+			return g.glob.Match(strings.Join(components, "/"))
+		}
+
+		return g.glob.Match(components[len(components)-1])
+	})
+}
+
+// walker walks a directory tree, according to the configured walkOptions.
+type walker struct {
+	fsys    walkFS
+	options walkOptions
+	include []pathGlob
+	exclude []pathGlob
+
+	// visited holds the real path of each walked directory, so that symlink
+	// loops are not followed.
+	visited map[string]bool
+}
+
+// newWalker returns a walker for the given walkFS and walkOptions.
+func newWalker(fsys walkFS, options walkOptions) (*walker, error) {
+	if len(options.include) == 0 {
+		options.include = []string{"*.yaml"}
+	}
+
+	include, err := newPathGlobs(options.include)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude, err := newPathGlobs(options.exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	return &walker{
+		fsys:    fsys,
+		options: options,
+		include: include,
+		exclude: exclude,
+		visited: make(map[string]bool),
+	}, nil
+}
+
+// walk walks the given directory. The dirFn callback is executed with each
+// directory (including the given directory) before it is walked, and can
+// return filepath.SkipDir to skip walking it. The fileFn callback is executed
+// with each included file.
+func (w *walker) walk(directory string, dirFn func(string) error, fileFn func(string)) error {
+	if err := dirFn(directory); err != nil {
+		if errors.Is(err, filepath.SkipDir) {
+			return nil
+		}
+
+		return err
+	}
+
+	return w.walkDirectory(directory, nil, nil, dirFn, fileFn)
+}
+
+// walkDirectory walks the given directory, which is located at the given path
+// components relative to the walked directory. The given gitignore patterns
+// are those read from each parent directory.
+func (w *walker) walkDirectory(directory string, components []string, patterns []gitignore.Pattern, dirFn func(string) error, fileFn func(string)) error {
+	if realPath := w.fsys.realPath(directory); realPath != "" {
+		if w.visited[realPath] {
+			return nil
+		}
+
+		w.visited[realPath] = true
+	}
+
+	if !w.options.noIgnoreFiles {
+		for _, name := range ignoreFiles {
+			patterns = append(slices.Clip(patterns), w.readIgnoreFile(filepath.Join(directory, name), components)...)
+		}
+	}
+
+	ignored := gitignore.NewMatcher(patterns)
+
+	entries, err := w.fsys.readDir(directory)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(directory, entry.Name())
+		relComponents := append(slices.Clip(components), entry.Name())
+		isDir := entry.IsDir()
+
+		if entry.Type()&fs.ModeSymlink != 0 {
+			info, err := w.fsys.stat(path)
+			if err != nil {
+				// Ignore broken symlinks.
+				continue
+			}
+
+			if isDir = info.IsDir(); isDir && !w.options.followSymlinks {
+				continue
+			}
+		}
+
+		switch {
+		case matchAny(w.exclude, relComponents), ignored.Match(relComponents, isDir):
+			// Ignore anything that is explicitly excluded or ignored.
+			continue
+
+		case isDir:
+			// Completely stop recursing into the .git and node_modules
+			// directories, and into any directories beyond the maximum depth.
+			if entry.Name() == ".git" || entry.Name() == "node_modules" {
+				continue
+			}
+
+			if w.options.maxDepth > 0 && len(relComponents) >= w.options.maxDepth {
+				continue
+			}
+
+			if err := dirFn(path); err != nil {
+				if errors.Is(err, filepath.SkipDir) {
+					continue
+				}
+
+				return err
+			}
+
+			if err := w.walkDirectory(path, relComponents, patterns, dirFn, fileFn); err != nil {
+				return err
+			}
+
+		case matchAny(w.include, relComponents):
+			fileFn(path)
+		}
+	}
+
+	return nil
+}
+
+// readIgnoreFile reads the gitignore-style patterns from the given file, which
+// is located at the given path components relative to the walked directory.
+// Nothing is returned if the file cannot be read.
+func (w *walker) readIgnoreFile(filename string, domain []string) []gitignore.Pattern {
+	file, err := w.fsys.open(filename)
+	if err != nil {
+		return nil
+	}
+	defer file.Close() //nolint:errcheck
+
+	var patterns []gitignore.Pattern
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		patterns = append(patterns, gitignore.ParsePattern(line, domain))
+	}
+
+	return patterns
+}
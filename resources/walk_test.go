@@ -0,0 +1,109 @@
+// Copyright Josh Komoroske. All rights reserved.
+// Use of this source code is governed by the MIT license,
+// a copy of which can be found in the LICENSE.txt file.
+// SPDX-License-Identifier: MIT
+
+package resources_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/joshdk/krf/resources"
+)
+
+func TestDirectoryWalk(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	directory := t.TempDir()
+
+	files := map[string]string{
+		"a.yaml":             "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n",
+		"b.yml":              "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n",
+		"c.json":             `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "c"}}`,
+		".gitignore":         "# Generated files.\ngenerated/\n",
+		"generated/d.yaml":   "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: d\n",
+		"vendor/e.yaml":      "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: e\n",
+		"sub/.krfignore":     "f.yaml\n",
+		"sub/f.yaml":         "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: f\n",
+		"sub/g.yaml":         "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: g\n",
+		"sub/deeper/h.yaml":  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: h\n",
+		"node_modules/i.yml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: i\n",
+	}
+
+	for name, contents := range files {
+		filename := filepath.Join(directory, name)
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filename, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Create a symlink to a directory, along with a symlink loop.
+	if err := os.Symlink(filepath.Join(directory, "sub", "deeper"), filepath.Join(directory, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(directory, filepath.Join(directory, "sub", "loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		options  []resources.Option
+		expected []string
+	}{
+		"defaults": {
+			expected: []string{"a", "h", "g", "e"},
+		},
+		"include": {
+			options:  []resources.Option{resources.WithInclude("*.yml", "*.json")},
+			expected: []string{"b", "c"},
+		},
+		"include path": {
+			options:  []resources.Option{resources.WithInclude("sub/**.yaml")},
+			expected: []string{"h", "g"},
+		},
+		"exclude": {
+			options:  []resources.Option{resources.WithExclude("vendor", "deeper")},
+			expected: []string{"a", "g"},
+		},
+		"without ignore files": {
+			options:  []resources.Option{resources.WithoutIgnoreFiles()},
+			expected: []string{"a", "d", "h", "f", "g", "e"},
+		},
+		"follow symlinks": {
+			options:  []resources.Option{resources.WithFollowSymlinks(), resources.WithExclude("sub/deeper")},
+			expected: []string{"a", "h", "g", "e"},
+		},
+		"max depth": {
+			options:  []resources.Option{resources.WithMaxDepth(2)},
+			expected: []string{"a", "g", "e"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var names []string
+
+			err := resources.Directory(directory, func(item resources.Resource) {
+				names = append(names, item.GetName())
+			}, test.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.expected, names); diff != "" {
+				t.Errorf("unexpected resources (-want +got):\n%s", diff)
+			}
+		})
+	}
+}